@@ -0,0 +1,357 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Key is a verification key.
+type Key struct {
+	// ID is matched against the token "kid" header.
+	// Optional.
+	ID string
+
+	// Algorithm restricts the key to one algorithm.
+	// Optional.
+	Algorithm string
+
+	// Key is a []byte secret, *rsa.PublicKey, *ecdsa.PublicKey or
+	// ed25519.PublicKey.
+	Key any
+}
+
+// KeySet provides verification keys.
+type KeySet interface {
+	// Keys returns the keys with ID kid, or every key when kid is empty.
+	Keys(ctx context.Context, kid string) ([]Key, error)
+}
+
+// StaticKeys is a fixed KeySet.
+type StaticKeys []Key
+
+// Keys implements KeySet.
+func (s StaticKeys) Keys(_ context.Context, kid string) ([]Key, error) {
+	return matchKeys(s, kid), nil
+}
+
+func matchKeys(keys []Key, kid string) []Key {
+	if kid == "" {
+		return keys
+	}
+	var matched []Key
+	for _, key := range keys {
+		if key.ID == kid {
+			matched = append(matched, key)
+		}
+	}
+	return matched
+}
+
+// jwk is a JSON Web Key as defined by RFC 7517.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	K         string `json:"k"`
+}
+
+// ParseJWKS parses a JSON Web Key Set. Keys of unsupported types and keys
+// not meant for signatures are skipped.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: parse JWKS: %w", err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if errors.Is(err, ErrUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwt: parse JWK %q: %w", k.KeyID, err)
+		}
+		keys = append(keys, Key{ID: k.KeyID, Algorithm: k.Algorithm, Key: key})
+	}
+	return keys, nil
+}
+
+func (k jwk) key() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.KeyType {
+	case "oct":
+		return decode(k.K)
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		// Uncompressed SEC 1 point, validated by ecdsa.ParseUncompressedPublicKey.
+		point := append([]byte{4}, append(leftPad(x, 32), leftPad(y, 32)...)...)
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
+
+// JWKSConfig defines the config for JWKS.
+type JWKSConfig struct {
+	// URL of the key set, for example the jwks_uri of an OpenID provider.
+	// Either URL or File is required.
+	URL string
+
+	// File is a local key set file. It is reloaded when its modification
+	// time changes.
+	File string
+
+	// RefreshInterval is how long the key set is cached, default is 1h.
+	// Optional.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval bounds how often an unknown "kid" triggers an early
+	// refresh, so that rotated keys are picked up without letting invalid
+	// tokens hammer the provider, and how often failed refreshes are
+	// retried, default is 1m.
+	// Optional.
+	MinRefreshInterval time.Duration
+
+	// HTTPClient fetches URL, default is a client with a 10s timeout.
+	// Optional.
+	HTTPClient *http.Client
+}
+
+// JWKS is a KeySet loaded from a JSON Web Key Set file or URL. It caches the
+// keys and refreshes them periodically and when a token references an
+// unknown key ID. Failed refreshes are retried once per MinRefreshInterval,
+// serving the cached keys meanwhile.
+type JWKS struct {
+	config JWKSConfig
+
+	mu          sync.Mutex
+	keys        []Key
+	fetched     time.Time
+	modTime     time.Time
+	lastAttempt time.Time
+	err         error
+	loading     *jwksLoad
+
+	now func() time.Time
+}
+
+// jwksLoad is a refresh in progress, shared by concurrent callers.
+type jwksLoad struct {
+	done chan struct{}
+	err  error
+}
+
+var _ KeySet = (*JWKS)(nil)
+
+// NewJWKS returns a JWKS. Keys are loaded on first use.
+func NewJWKS(config JWKSConfig) *JWKS {
+	if config.URL == "" && config.File == "" {
+		panic("jwt: JWKSConfig requires URL or File")
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Hour
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = time.Minute
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKS{config: config, now: time.Now}
+}
+
+// Keys implements KeySet.
+func (j *JWKS) Keys(ctx context.Context, kid string) ([]Key, error) {
+	var modTime time.Time
+	if j.config.File != "" {
+		if info, err := os.Stat(j.config.File); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+
+	j.mu.Lock()
+	now := j.now()
+	stale := j.fetched.IsZero() || now.Sub(j.fetched) >= j.config.RefreshInterval ||
+		!modTime.IsZero() && !modTime.Equal(j.modTime)
+	// Failed refreshes are retried once per MinRefreshInterval, and callers
+	// with cached keys do not wait for a refresh in progress.
+	refresh := stale && (j.err == nil || now.Sub(j.lastAttempt) >= j.config.MinRefreshInterval) &&
+		(j.loading == nil || j.fetched.IsZero())
+	j.mu.Unlock()
+
+	if refresh {
+		_ = j.load(ctx)
+	}
+
+	j.mu.Lock()
+	if j.fetched.IsZero() {
+		err := j.err
+		j.mu.Unlock()
+		if err == nil {
+			err = ctx.Err()
+		}
+		return nil, err
+	}
+	keys := matchKeys(j.keys, kid)
+	// The key may have been rotated in since the last fetch.
+	rotated := len(keys) == 0 && kid != "" && j.loading == nil &&
+		now.Sub(j.lastAttempt) >= j.config.MinRefreshInterval
+	j.mu.Unlock()
+
+	if rotated && j.load(ctx) == nil {
+		j.mu.Lock()
+		keys = matchKeys(j.keys, kid)
+		j.mu.Unlock()
+	}
+	return keys, nil
+}
+
+// Refresh reloads the key set.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	return j.load(ctx)
+}
+
+// load reloads the key set, or waits for the refresh in progress. The lock is
+// not held while loading.
+func (j *JWKS) load(ctx context.Context) error {
+	j.mu.Lock()
+	if l := j.loading; l != nil {
+		j.mu.Unlock()
+		select {
+		case <-l.done:
+			return l.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	l := &jwksLoad{done: make(chan struct{})}
+	j.loading = l
+	now := j.now()
+	j.lastAttempt = now
+	j.mu.Unlock()
+
+	// The refresh is shared, so it outlives the context of the caller
+	// starting it; HTTPClient bounds it.
+	keys, modTime, err := j.read(context.WithoutCancel(ctx))
+
+	j.mu.Lock()
+	if err == nil {
+		j.keys = keys
+		j.fetched = now
+		j.modTime = modTime
+	}
+	j.err = err
+	j.loading = nil
+	j.mu.Unlock()
+
+	l.err = err
+	close(l.done)
+	return err
+}
+
+// read loads and parses the key set.
+func (j *JWKS) read(ctx context.Context) ([]Key, time.Time, error) {
+	var (
+		data    []byte
+		modTime time.Time
+		err     error
+	)
+	if j.config.File != "" {
+		var info os.FileInfo
+		if info, err = os.Stat(j.config.File); err == nil {
+			modTime = info.ModTime()
+			data, err = os.ReadFile(j.config.File)
+		}
+	} else {
+		data, err = j.fetch(ctx)
+	}
+	if err != nil {
+		return nil, modTime, fmt.Errorf("jwt: load JWKS: %w", err)
+	}
+
+	keys, err := ParseJWKS(data)
+	return keys, modTime, err
+}
+
+func (j *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := j.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,235 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (k testKeys) jwks(t *testing.T, kidSuffix string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hs" + kidSuffix, "k": b64(k.secret)},
+		{"kty": "RSA", "kid": "rs" + kidSuffix, "alg": "RS256", "use": "sig",
+			"n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "es" + kidSuffix, "crv": "P-256",
+			"x": b64(k.ec.X.Bytes()), "y": b64(k.ec.Y.Bytes())},
+		{"kty": "OKP", "kid": "ed" + kidSuffix, "crv": "Ed25519", "x": b64(k.ed.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(k.rsa.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "AA", "y": "AA"},
+	}})
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	parsed, err := ParseJWKS(keys.jwks(t, ""))
+	require.NoError(t, err)
+	require.Len(t, parsed, 4)
+
+	verifier := NewVerifier(VerifierConfig{Keys: StaticKeys(parsed)})
+	for _, tt := range []struct {
+		alg string
+		key any
+		kid string
+	}{
+		{HS256, keys.secret, "hs"},
+		{RS256, keys.rsa, "rs"},
+		{ES256, keys.ec, "es"},
+		{EdDSA, keys.ed, "ed"},
+	} {
+		token, err := Sign(map[string]any{"sub": tt.kid}, tt.alg, tt.key, tt.kid)
+		require.NoError(t, err)
+		claims, err := verifier.Verify(context.Background(), token)
+		require.NoError(t, err, tt.alg)
+		assert.Equal(t, tt.kid, claims.Subject)
+	}
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AA"}]}`))
+	require.Error(t, err)
+	_, err = ParseJWKS([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestJWKS_URLRotation(t *testing.T) {
+	oldKeys, newKeys := newTestKeys(t), newTestKeys(t)
+
+	var (
+		requests atomic.Int32
+		current  atomic.Value
+	)
+	current.Store(oldKeys.jwks(t, "-1"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jwks := NewJWKS(JWKSConfig{URL: server.URL, RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	jwks.now = func() time.Time { return now }
+	verifier := NewVerifier(VerifierConfig{Keys: jwks})
+
+	token, err := Sign(map[string]any{}, EdDSA, oldKeys.ed, "ed-1")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "keys are cached")
+
+	// The provider rotates keys; an unknown kid triggers a refresh once the
+	// minimum interval has passed.
+	current.Store(newKeys.jwks(t, "-2"))
+	rotated, err := Sign(map[string]any{}, EdDSA, newKeys.ed, "ed-2")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), rotated)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, int32(1), requests.Load())
+
+	now = now.Add(2 * time.Minute)
+	_, err = verifier.Verify(context.Background(), rotated)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// An unknown kid right after does not refetch.
+	unknown, err := Sign(map[string]any{}, EdDSA, newKeys.ed, "nope")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), unknown)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestJWKS_FailedRefresh(t *testing.T) {
+	keys := newTestKeys(t)
+
+	var (
+		requests atomic.Int32
+		failing  atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(keys.jwks(t, "-1"))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jwks := NewJWKS(JWKSConfig{URL: server.URL, RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	jwks.now = func() time.Time { return now }
+
+	found, err := jwks.Keys(context.Background(), "rs-1")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	// The provider is down when the keys expire: the cached keys are served
+	// and the refresh is retried once per minimum interval.
+	failing.Store(true)
+	now = now.Add(2 * time.Hour)
+	for range 3 {
+		found, err = jwks.Keys(context.Background(), "rs-1")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+
+	now = now.Add(2 * time.Minute)
+	_, err = jwks.Keys(context.Background(), "rs-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+
+	failing.Store(false)
+	now = now.Add(2 * time.Minute)
+	_, err = jwks.Keys(context.Background(), "rs-1")
+	require.NoError(t, err)
+	_, err = jwks.Keys(context.Background(), "rs-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestJWKS_InitialLoadFailure(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	jwks := NewJWKS(JWKSConfig{URL: server.URL})
+	for range 3 {
+		_, err := jwks.Keys(context.Background(), "")
+		require.Error(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load(), "failed loads are not retried on every request")
+}
+
+func TestJWKS_ConcurrentLoad(t *testing.T) {
+	keys := newTestKeys(t)
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write(keys.jwks(t, "-1"))
+	}))
+	defer server.Close()
+
+	jwks := NewJWKS(JWKSConfig{URL: server.URL})
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			found, err := jwks.Keys(context.Background(), "rs-1")
+			assert.NoError(t, err)
+			assert.Len(t, found, 1)
+		})
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), requests.Load(), "concurrent callers share one fetch")
+}
+
+func TestJWKS_File(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keys.jwks(t, "-1"), 0o600))
+
+	jwks := NewJWKS(JWKSConfig{File: path})
+	found, err := jwks.Keys(context.Background(), "rs-1")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	// Rewriting the file reloads it.
+	require.NoError(t, os.WriteFile(path, keys.jwks(t, "-2"), 0o600))
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, later, later))
+	found, err = jwks.Keys(context.Background(), "rs-2")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	_, err = NewJWKS(JWKSConfig{File: filepath.Join(t.TempDir(), "missing.json")}).Keys(context.Background(), "")
+	require.Error(t, err)
+	assert.Panics(t, func() { NewJWKS(JWKSConfig{}) })
+}
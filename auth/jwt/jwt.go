@@ -0,0 +1,422 @@
+// Package jwt verifies JSON Web Tokens and provides a fox middleware that
+// authenticates requests with them.
+//
+// Supported algorithms are HS256, RS256, ES256 and EdDSA (Ed25519). Keys come
+// from a KeySet: StaticKeys for keys known at startup, or a JWKS loaded from a
+// local file or URL and refreshed on key rotation.
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fox-gonic/fox"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// Algorithms lists the supported signing algorithms.
+var Algorithms = []string{HS256, RS256, ES256, EdDSA}
+
+// Verification errors. Errors returned by Verifier.Verify wrap one of them.
+var (
+	ErrMalformed       = errors.New("jwt: malformed token")
+	ErrAlgorithm       = errors.New("jwt: unsupported or disallowed algorithm")
+	ErrKeyNotFound     = errors.New("jwt: no key found for token")
+	ErrSignature       = errors.New("jwt: invalid signature")
+	ErrExpired         = errors.New("jwt: token is expired")
+	ErrNotYetValid     = errors.New("jwt: token is not valid yet")
+	ErrInvalidIssuer   = errors.New("jwt: invalid issuer")
+	ErrInvalidAudience = errors.New("jwt: invalid audience")
+	ErrUnsupportedKey  = errors.New("jwt: unsupported key type")
+	ErrKeyTypeMismatch = errors.New("jwt: key does not match algorithm")
+)
+
+// NumericDate is a JWT date, encoded as seconds since the Unix epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns t truncated to seconds.
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t.Truncate(time.Second)}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprint(d.Unix())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	sec := int64(f)
+	d.Time = time.Unix(sec, int64((f-float64(sec))*1e9))
+	return nil
+}
+
+// Audience is the "aud" claim, a single string or an array of strings.
+type Audience []string
+
+// MarshalJSON implements json.Marshaler.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Claims are the registered JWT claims. The full payload is available in Raw
+// and can be decoded into a custom type with Decode.
+type Claims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+
+	// Raw is the decoded token payload.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Decode unmarshals the token payload into v.
+func (c *Claims) Decode(v any) error {
+	return json.Unmarshal(c.Raw, v)
+}
+
+// Get returns the payload claim name, or nil.
+func (c *Claims) Get(name string) any {
+	var m map[string]any
+	if err := json.Unmarshal(c.Raw, &m); err != nil {
+		return nil
+	}
+	return m[name]
+}
+
+// Grants returns the roles, scopes and permissions of the token from the
+// "roles", "scope" (space separated, RFC 8693) or "scp", and "permissions"
+// claims, as checked by fox authorization policies.
+func (c *Claims) Grants() fox.Grants {
+	var payload struct {
+		Roles       Audience `json:"roles"`
+		Scope       string   `json:"scope"`
+		Scp         Audience `json:"scp"`
+		Permissions Audience `json:"permissions"`
+	}
+	if err := json.Unmarshal(c.Raw, &payload); err != nil {
+		return fox.Grants{}
+	}
+	scopes := []string(payload.Scp)
+	if payload.Scope != "" {
+		scopes = strings.Fields(payload.Scope)
+	}
+	return fox.Grants{
+		Roles:       payload.Roles,
+		Scopes:      scopes,
+		Permissions: payload.Permissions,
+	}
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// VerifierConfig defines the config for Verifier.
+type VerifierConfig struct {
+	// Keys provides the verification keys. Required.
+	Keys KeySet
+
+	// Algorithms restricts the accepted algorithms, default is Algorithms.
+	// Optional.
+	Algorithms []string
+
+	// Issuer is the required "iss" claim.
+	// Optional.
+	Issuer string
+
+	// Audience lists accepted "aud" values; the token must contain one of
+	// them.
+	// Optional.
+	Audience []string
+
+	// ClockSkew is the tolerance applied to "exp" and "nbf".
+	// Optional.
+	ClockSkew time.Duration
+
+	// Now returns the current time, default is time.Now.
+	// Optional.
+	Now func() time.Time
+}
+
+// Verifier verifies token signatures and claims.
+type Verifier struct {
+	config VerifierConfig
+}
+
+// NewVerifier returns a Verifier. It panics when config.Keys is nil or an
+// algorithm is not supported.
+func NewVerifier(config VerifierConfig) *Verifier {
+	if config.Keys == nil {
+		panic("jwt: VerifierConfig.Keys is required")
+	}
+	if len(config.Algorithms) == 0 {
+		config.Algorithms = Algorithms
+	}
+	for _, alg := range config.Algorithms {
+		if !slices.Contains(Algorithms, alg) {
+			panic("jwt: unsupported algorithm " + alg)
+		}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Verifier{config: config}
+}
+
+// Verify checks the signature and claims of token and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrMalformed
+	}
+	if !slices.Contains(v.config.Algorithms, h.Algorithm) {
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithm, h.Algorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	keys, err := v.config.Keys.Keys(ctx, h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if key.Algorithm != "" && key.Algorithm != h.Algorithm {
+			continue
+		}
+		if verifySignature(h.Algorithm, key.Key, signed, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		if len(keys) == 0 {
+			return nil, ErrKeyNotFound
+		}
+		return nil, ErrSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	claims := &Claims{Raw: payload}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrMalformed
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(claims *Claims) error {
+	now := v.config.Now()
+	skew := v.config.ClockSkew
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Add(skew)) {
+		return ErrExpired
+	}
+	if claims.NotBefore != nil && now.Add(skew).Before(claims.NotBefore.Time) {
+		return ErrNotYetValid
+	}
+	if v.config.Issuer != "" && claims.Issuer != v.config.Issuer {
+		return ErrInvalidIssuer
+	}
+	if len(v.config.Audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.config.Audience, aud)
+	}) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func verifySignature(alg string, key any, signed, signature []byte) error {
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrKeyTypeMismatch
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrSignature
+		}
+		return nil
+	case RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrKeyTypeMismatch
+		}
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return ErrSignature
+		}
+		return nil
+	case ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().BitSize != 256 {
+			return ErrKeyTypeMismatch
+		}
+		if len(signature) != 64 {
+			return ErrSignature
+		}
+		digest := sha256.Sum256(signed)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrSignature
+		}
+		return nil
+	case EdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrKeyTypeMismatch
+		}
+		if !ed25519.Verify(pub, signed, signature) {
+			return ErrSignature
+		}
+		return nil
+	}
+	return ErrAlgorithm
+}
+
+// Sign returns a token for claims, which may be *Claims or any value
+// marshaling to a JSON object, signed with key: a []byte secret for HS256,
+// an *rsa.PrivateKey for RS256, an *ecdsa.PrivateKey for ES256 or an
+// ed25519.PrivateKey for EdDSA. kid is set as the "kid" header when not
+// empty.
+func Sign(claims any, alg string, key any, kid string) (string, error) {
+	headerJSON, err := json.Marshal(header{Algorithm: alg, KeyID: kid, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(payload, []byte("{")) {
+		return "", fmt.Errorf("%w: claims must be a JSON object", ErrMalformed)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := sign(alg, key, []byte(signed))
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func sign(alg string, key any, signed []byte) ([]byte, error) {
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, ErrKeyTypeMismatch
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil), nil
+	case RS256:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrKeyTypeMismatch
+		}
+		digest := sha256.Sum256(signed)
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	case ES256:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve.Params().BitSize != 256 {
+			return nil, ErrKeyTypeMismatch
+		}
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	case EdDSA:
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrKeyTypeMismatch
+		}
+		return ed25519.Sign(priv, signed), nil
+	}
+	return nil, ErrAlgorithm
+}
@@ -0,0 +1,170 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	secret []byte
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+	ed     ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testKeys{secret: []byte("0123456789abcdef0123456789abcdef"), rsa: rsaKey, ec: ecKey, ed: edKey}
+}
+
+func (k testKeys) static() StaticKeys {
+	return StaticKeys{
+		{ID: "hs", Key: k.secret},
+		{ID: "rs", Key: &k.rsa.PublicKey},
+		{ID: "es", Key: &k.ec.PublicKey},
+		{ID: "ed", Key: k.ed.Public()},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := NewVerifier(VerifierConfig{Keys: keys.static()})
+
+	tests := []struct {
+		alg string
+		key any
+		kid string
+	}{
+		{HS256, keys.secret, "hs"},
+		{RS256, keys.rsa, "rs"},
+		{ES256, keys.ec, "es"},
+		{EdDSA, keys.ed, "ed"},
+		{EdDSA, keys.ed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg+"/"+tt.kid, func(t *testing.T) {
+			token, err := Sign(map[string]any{"sub": "alice", "role": "admin"}, tt.alg, tt.key, tt.kid)
+			require.NoError(t, err)
+
+			claims, err := verifier.Verify(context.Background(), token)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", claims.Subject)
+			assert.Equal(t, "admin", claims.Get("role"))
+
+			// Tampered payloads fail.
+			parts := strings.Split(token, ".")
+			forged, err := Sign(map[string]any{"sub": "mallory"}, tt.alg, tt.key, tt.kid)
+			require.NoError(t, err)
+			parts[1] = strings.Split(forged, ".")[1]
+			_, err = verifier.Verify(context.Background(), strings.Join(parts, "."))
+			require.ErrorIs(t, err, ErrSignature)
+		})
+	}
+}
+
+func TestVerify_AlgorithmConfusion(t *testing.T) {
+	keys := newTestKeys(t)
+
+	// An HS256 token signed with the RSA public key bytes must not verify
+	// against the RSA key.
+	verifier := NewVerifier(VerifierConfig{Keys: StaticKeys{{ID: "rs", Key: &keys.rsa.PublicKey}}})
+	token, err := Sign(map[string]any{"sub": "x"}, HS256, keys.rsa.N.Bytes(), "rs")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrSignature)
+
+	// Disallowed and "none" algorithms are rejected before any key lookup.
+	verifier = NewVerifier(VerifierConfig{Keys: keys.static(), Algorithms: []string{RS256}})
+	token, err = Sign(map[string]any{"sub": "x"}, HS256, keys.secret, "hs")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrAlgorithm)
+
+	_, err = verifier.Verify(context.Background(), "eyJhbGciOiJub25lIn0.e30.")
+	require.ErrorIs(t, err, ErrAlgorithm)
+
+	assert.Panics(t, func() { NewVerifier(VerifierConfig{Keys: keys.static(), Algorithms: []string{"none"}}) })
+	assert.Panics(t, func() { NewVerifier(VerifierConfig{}) })
+}
+
+func TestVerify_Claims(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	verifier := NewVerifier(VerifierConfig{
+		Keys:      keys.static(),
+		Issuer:    "https://issuer.example",
+		Audience:  []string{"api"},
+		ClockSkew: 30 * time.Second,
+		Now:       func() time.Time { return now },
+	})
+
+	valid := Claims{
+		Issuer:    "https://issuer.example",
+		Audience:  Audience{"web", "api"},
+		ExpiresAt: NewNumericDate(now.Add(time.Minute)),
+		NotBefore: NewNumericDate(now.Add(-time.Minute)),
+	}
+	verify := func(mutate func(c *Claims)) error {
+		claims := valid
+		mutate(&claims)
+		token, err := Sign(claims, HS256, keys.secret, "hs")
+		require.NoError(t, err)
+		_, err = verifier.Verify(context.Background(), token)
+		return err
+	}
+
+	require.NoError(t, verify(func(*Claims) {}))
+	// Within the clock skew.
+	require.NoError(t, verify(func(c *Claims) { c.ExpiresAt = NewNumericDate(now.Add(-10 * time.Second)) }))
+	require.NoError(t, verify(func(c *Claims) { c.NotBefore = NewNumericDate(now.Add(10 * time.Second)) }))
+
+	require.ErrorIs(t, verify(func(c *Claims) { c.ExpiresAt = NewNumericDate(now.Add(-time.Minute)) }), ErrExpired)
+	require.ErrorIs(t, verify(func(c *Claims) { c.NotBefore = NewNumericDate(now.Add(time.Minute)) }), ErrNotYetValid)
+	require.ErrorIs(t, verify(func(c *Claims) { c.Issuer = "other" }), ErrInvalidIssuer)
+	require.ErrorIs(t, verify(func(c *Claims) { c.Audience = Audience{"web"} }), ErrInvalidAudience)
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := NewVerifier(VerifierConfig{Keys: keys.static()})
+
+	for _, token := range []string{"", "a.b", "!!.e30.sig", "eyJhbGciOiJIUzI1NiJ9.e30.!!"} {
+		_, err := verifier.Verify(context.Background(), token)
+		require.Error(t, err, token)
+	}
+
+	token, err := Sign(map[string]any{}, HS256, keys.secret, "unknown")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = Sign("not an object", HS256, keys.secret, "")
+	require.ErrorIs(t, err, ErrMalformed)
+	_, err = Sign(map[string]any{}, RS256, keys.secret, "")
+	require.ErrorIs(t, err, ErrKeyTypeMismatch)
+}
+
+func TestAudience_JSON(t *testing.T) {
+	var claims Claims
+	require.NoError(t, (&Claims{Raw: []byte(`{"aud":"api"}`)}).Decode(&claims))
+	assert.Equal(t, Audience{"api"}, claims.Audience)
+	require.NoError(t, (&Claims{Raw: []byte(`{"aud":["a","b"],"exp":1767225600}`)}).Decode(&claims))
+	assert.Equal(t, Audience{"a", "b"}, claims.Audience)
+	assert.Equal(t, int64(1767225600), claims.ExpiresAt.Unix())
+}
@@ -0,0 +1,214 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox"
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// DefaultContextKey is the gin context key the claims are stored under, so
+// that bound structs receive them with a `context:"claims"` tag.
+const DefaultContextKey = "claims"
+
+// claimsContextKey always stores the *Claims, even with Config.Claims.
+const claimsContextKey = "_fox-gonic/fox/auth/jwt/claims"
+
+// Authentication errors rendered by Middleware.
+var (
+	// ErrTokenMissing is returned when the request carries no token.
+	ErrTokenMissing = &httperrors.Error{
+		HTTPCode: http.StatusUnauthorized,
+		Err:      errors.New("missing token"),
+		Code:     "TOKEN_MISSING",
+	}
+
+	// ErrTokenInvalid is returned when the token fails verification.
+	ErrTokenInvalid = &httperrors.Error{
+		HTTPCode: http.StatusUnauthorized,
+		Err:      errors.New("invalid token"),
+		Code:     "TOKEN_INVALID",
+	}
+
+	// ErrTokenExpired is returned when the token is expired.
+	ErrTokenExpired = &httperrors.Error{
+		HTTPCode: http.StatusUnauthorized,
+		Err:      errors.New("token is expired"),
+		Code:     "TOKEN_EXPIRED",
+	}
+)
+
+// Config defines the config for Middleware.
+type Config struct {
+	// Verifier checks the tokens. Required.
+	Verifier *Verifier
+
+	// Header carries the token, default is "Authorization" with the Bearer
+	// scheme. Other headers carry the bare token.
+	// Optional.
+	Header string
+
+	// Cookie is the name of a cookie carrying the token, used when the
+	// header is absent.
+	// Optional.
+	Cookie string
+
+	// ContextKey is the gin context key the claims are stored under, default
+	// is "claims".
+	// Optional.
+	ContextKey string
+
+	// Claims returns a new value to decode the token payload into, for
+	// example func() any { return new(MyClaims) }. It is stored under
+	// ContextKey instead of *Claims, so that handlers bind their own claims
+	// type; *Claims remains available from ClaimsFromContext.
+	// Optional.
+	Claims func() any
+
+	// SubjectKey also stores the "sub" claim under this gin context key, for
+	// example for fox.RateLimitByContextValue.
+	// Optional.
+	SubjectKey string
+
+	// Principal returns the caller checked by fox authorization policies such
+	// as fox.RequireScopes, default is Claims.Grants.
+	// Optional.
+	Principal func(claims *Claims) fox.Principal
+
+	// Realm is sent in the WWW-Authenticate challenge.
+	// Optional.
+	Realm string
+
+	// Optional lets requests without a token through unauthenticated.
+	// Invalid tokens are still rejected.
+	// Optional.
+	Optional bool
+}
+
+// Middleware returns a middleware that authenticates requests with a JWT from
+// the Authorization header or a cookie and stores its claims in the gin
+// context. Handlers receive them through a `context:"claims"` tag:
+//
+//	type CreateOrder struct {
+//		Claims *jwt.Claims `context:"claims"`
+//		Item   string      `json:"item"`
+//	}
+//
+// Missing, invalid and expired tokens are answered with 401 ErrTokenMissing,
+// ErrTokenInvalid and ErrTokenExpired and a Bearer WWW-Authenticate
+// challenge. The caller is stored with fox.SetPrincipal for authorization
+// policies. The scheme is recorded as the "authentication" route metadata.
+func Middleware(config Config) *fox.Middleware {
+	if config.Verifier == nil {
+		panic("jwt: Config.Verifier is required")
+	}
+	if config.Header == "" {
+		config.Header = "Authorization"
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultContextKey
+	}
+
+	meta := map[string]any{"scheme": "bearer", "bearerFormat": "JWT"}
+	if config.Cookie != "" {
+		meta["cookie"] = config.Cookie
+	}
+	if config.Optional {
+		meta["optional"] = true
+	}
+
+	return &fox.Middleware{
+		Handler: func(c *gin.Context) {
+			token := extractToken(c, config.Header, config.Cookie)
+			if token == "" {
+				if config.Optional {
+					return
+				}
+				unauthorized(c, config.Realm, ErrTokenMissing, "")
+				return
+			}
+
+			claims, err := config.Verifier.Verify(c.Request.Context(), token)
+			if err != nil {
+				if errors.Is(err, ErrExpired) {
+					unauthorized(c, config.Realm, ErrTokenExpired, "The token is expired")
+				} else {
+					unauthorized(c, config.Realm, ErrTokenInvalid, "The token is invalid")
+				}
+				_ = c.Error(err)
+				return
+			}
+
+			var value any = claims
+			if config.Claims != nil {
+				value = config.Claims()
+				if err := claims.Decode(value); err != nil {
+					unauthorized(c, config.Realm, ErrTokenInvalid, "The token claims are invalid")
+					_ = c.Error(err)
+					return
+				}
+			}
+			c.Set(claimsContextKey, claims)
+			c.Set(config.ContextKey, value)
+			if config.SubjectKey != "" {
+				c.Set(config.SubjectKey, claims.Subject)
+			}
+			if config.Principal != nil {
+				fox.SetPrincipal(c, config.Principal(claims))
+			} else {
+				fox.SetPrincipal(c, claims.Grants())
+			}
+		},
+		Meta: fox.RouteMeta{"authentication": meta},
+	}
+}
+
+// ClaimsFromContext returns the claims of the token authenticated by
+// Middleware, or nil.
+func ClaimsFromContext(c *gin.Context) *Claims {
+	if v, exists := c.Get(claimsContextKey); exists {
+		claims, _ := v.(*Claims)
+		return claims
+	}
+	return nil
+}
+
+func extractToken(c *gin.Context, headerName, cookie string) string {
+	if value := c.GetHeader(headerName); value != "" {
+		if !strings.EqualFold(headerName, "Authorization") {
+			return value
+		}
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if cookie != "" {
+		if value, err := c.Cookie(cookie); err == nil {
+			return value
+		}
+	}
+	return ""
+}
+
+// unauthorized renders err with a Bearer challenge as described by RFC 6750.
+func unauthorized(c *gin.Context, realm string, err *httperrors.Error, description string) {
+	params := []string{}
+	if realm != "" {
+		params = append(params, `realm="`+realm+`"`)
+	}
+	if description != "" {
+		params = append(params, `error="invalid_token"`, `error_description="`+description+`"`)
+	}
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	c.Header("WWW-Authenticate", challenge)
+	fox.AbortWithError(c, err)
+}
@@ -0,0 +1,191 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox"
+	"github.com/fox-gonic/fox/auth/jwt"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+type whoAmI struct {
+	Claims *jwt.Claims `context:"claims"`
+}
+
+type appClaims struct {
+	Subject string   `json:"sub"`
+	Roles   []string `json:"roles"`
+}
+
+func newVerifier() *jwt.Verifier {
+	return jwt.NewVerifier(jwt.VerifierConfig{Keys: jwt.StaticKeys{{Key: testSecret}}})
+}
+
+func sign(t *testing.T, claims any) string {
+	t.Helper()
+	token, err := jwt.Sign(claims, jwt.HS256, testSecret, "")
+	require.NoError(t, err)
+	return token
+}
+
+func serve(router http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_BindsClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := fox.New()
+	router.GET("/me", jwt.Middleware(jwt.Config{Verifier: newVerifier(), SubjectKey: "user"}),
+		func(c *fox.Context, in *whoAmI) (map[string]string, error) {
+			assert.Same(t, in.Claims, jwt.ClaimsFromContext(c.Context))
+			return map[string]string{"sub": in.Claims.Subject, "user": c.GetString("user")}, nil
+		})
+
+	token := sign(t, map[string]any{"sub": "alice"})
+	w := serve(router, "/me", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"sub":"alice","user":"alice"}`, w.Body.String())
+}
+
+func TestMiddleware_CustomClaimsAndCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		Claims *appClaims `context:"claims"`
+	}
+
+	router := fox.New()
+	router.GET("/roles", jwt.Middleware(jwt.Config{
+		Verifier: newVerifier(),
+		Cookie:   "access_token",
+		Claims:   func() any { return new(appClaims) },
+	}), func(c *fox.Context, in *request) []string {
+		return in.Claims.Roles
+	})
+
+	token := sign(t, map[string]any{"sub": "bob", "roles": []string{"admin"}})
+	w := serve(router, "/roles", "Cookie", "access_token="+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["admin"]`, w.Body.String())
+}
+
+func TestMiddleware_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := fox.New()
+	router.GET("/me", jwt.Middleware(jwt.Config{Verifier: newVerifier(), Realm: "api"}), func() string {
+		return "ok"
+	})
+
+	tests := []struct {
+		name      string
+		header    []string
+		code      string
+		challenge string
+	}{
+		{"missing", nil, "TOKEN_MISSING", `Bearer realm="api"`},
+		{"wrong scheme", []string{"Authorization", "Basic abc"}, "TOKEN_MISSING", `Bearer realm="api"`},
+		{"invalid", []string{"Authorization", "Bearer abc.def.ghi"}, "TOKEN_INVALID",
+			`Bearer realm="api", error="invalid_token", error_description="The token is invalid"`},
+		{"expired", []string{"Authorization", "Bearer " + sign(t, jwt.Claims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		})}, "TOKEN_EXPIRED",
+			`Bearer realm="api", error="invalid_token", error_description="The token is expired"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, "/me", tt.header...)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.challenge, w.Header().Get("WWW-Authenticate"))
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body["code"])
+		})
+	}
+
+	t.Run("envelope", func(t *testing.T) {
+		router.Envelope = fox.DefaultEnvelope{}
+		w := serve(router, "/me")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="api"`, w.Header().Get("WWW-Authenticate"))
+
+		var body struct {
+			Error     map[string]any `json:"error"`
+			RequestID string         `json:"request_id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "TOKEN_MISSING", body.Error["code"])
+		assert.NotEmpty(t, body.RequestID)
+	})
+}
+
+func TestMiddleware_OptionalAndMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := fox.New()
+	router.GET("/feed", jwt.Middleware(jwt.Config{Verifier: newVerifier(), Optional: true}),
+		func(c *fox.Context, in *whoAmI) string {
+			if in.Claims == nil {
+				return "anonymous"
+			}
+			return in.Claims.Subject
+		})
+
+	assert.Equal(t, "anonymous", serve(router, "/feed").Body.String())
+	assert.Equal(t, "carol", serve(router, "/feed", "Authorization", "Bearer "+sign(t, map[string]any{"sub": "carol"})).Body.String())
+	assert.Equal(t, http.StatusUnauthorized, serve(router, "/feed", "Authorization", "Bearer bad").Code)
+
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, map[string]any{"scheme": "bearer", "bearerFormat": "JWT", "optional": true},
+		routes[0].Meta["authentication"])
+}
+
+func TestMiddleware_Principal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := fox.New()
+	router.GET("/orders",
+		jwt.Middleware(jwt.Config{Verifier: newVerifier()}),
+		fox.RequireScopes("orders:read"),
+		fox.RequireRoles("clerk", "admin"),
+		func() string { return "ok" })
+
+	token := sign(t, map[string]any{"sub": "alice", "scope": "orders:read profile", "roles": "clerk"})
+	w := serve(router, "/orders", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	token = sign(t, map[string]any{"sub": "bob", "scp": []string{"profile"}, "roles": []string{"clerk"}})
+	w = serve(router, "/orders", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	manifest := fox.RouteManifestFromEngine(router)
+	require.Len(t, manifest.Routes, 1)
+	assert.Equal(t, []map[string][]string{{"bearer": {"orders:read"}}}, manifest.Routes[0].Security)
+}
+
+func TestClaims_Grants(t *testing.T) {
+	claims := &jwt.Claims{Raw: json.RawMessage(`{"scope":"a b","scp":["c"],"roles":["admin"],"permissions":"users:read"}`)}
+	assert.Equal(t, fox.Grants{
+		Roles:       []string{"admin"},
+		Scopes:      []string{"a", "b"},
+		Permissions: []string{"users:read"},
+	}, claims.Grants())
+}
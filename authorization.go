@@ -0,0 +1,214 @@
+package fox
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// principalContextKey stores the Principal of the current request.
+const principalContextKey = "_fox-gonic/fox/principal"
+
+// Principal is the authenticated caller that authorization policies are
+// checked against. Authentication middleware store it with SetPrincipal;
+// auth/jwt does so for the verified token claims.
+type Principal interface {
+	HasRole(role string) bool
+	HasScope(scope string) bool
+	HasPermission(permission string) bool
+}
+
+// Grants is a Principal with a fixed set of roles, scopes and permissions.
+type Grants struct {
+	Roles       []string
+	Scopes      []string
+	Permissions []string
+}
+
+var _ Principal = Grants{}
+
+// HasRole implements Principal.
+func (g Grants) HasRole(role string) bool { return slices.Contains(g.Roles, role) }
+
+// HasScope implements Principal.
+func (g Grants) HasScope(scope string) bool { return slices.Contains(g.Scopes, scope) }
+
+// HasPermission implements Principal.
+func (g Grants) HasPermission(permission string) bool {
+	return slices.Contains(g.Permissions, permission)
+}
+
+// SetPrincipal stores the authenticated caller of the request.
+func SetPrincipal(c *gin.Context, principal Principal) {
+	c.Set(principalContextKey, principal)
+}
+
+// PrincipalFromContext returns the Principal stored by SetPrincipal, or nil.
+func PrincipalFromContext(c *gin.Context) Principal {
+	if c == nil {
+		return nil
+	}
+	if v, exists := c.Get(principalContextKey); exists {
+		principal, _ := v.(Principal)
+		return principal
+	}
+	return nil
+}
+
+// Principal returns the authenticated caller of the request, or nil.
+func (c *Context) Principal() Principal {
+	return PrincipalFromContext(c.Context)
+}
+
+// AuthorizationPolicy describes what a caller needs to access a route.
+type AuthorizationPolicy struct {
+	// Name identifies the policy in the route metadata.
+	// Optional.
+	Name string
+
+	// Roles grants access to callers having any of the roles.
+	// Optional.
+	Roles []string
+
+	// Scopes must all be granted to the caller.
+	// Optional.
+	Scopes []string
+
+	// Permissions must all be granted to the caller.
+	// Optional.
+	Permissions []string
+
+	// Check is called after the requirements above are met. A returned
+	// *httperrors.Error is rendered as is, other errors deny the request with
+	// Error.
+	// Optional.
+	Check func(c *Context) error
+
+	// Error is rendered when the caller lacks a requirement, default is 403
+	// httperrors.ErrForbidden. Requests without a Principal are answered with
+	// 401 httperrors.ErrUnauthorized instead.
+	// Optional.
+	Error *httperrors.Error
+}
+
+// Authorize returns a middleware that checks the policy before the handlers of
+// the routes it is attached to. Policies attached to a group and its routes
+// must all pass. The requirements are recorded in the "authorization" route
+// metadata and the security section of the route manifest.
+//
+//	admin := router.Group("/admin", auth, fox.RequireRoles("admin"))
+//	admin.DELETE("/users/:id", fox.Authorize(fox.AuthorizationPolicy{
+//		Name:        "owner",
+//		Permissions: []string{"users:delete"},
+//		Check: func(c *fox.Context) error {
+//			...
+//		},
+//	}), DeleteUser)
+func Authorize(policy AuthorizationPolicy) *Middleware {
+	if policy.Error == nil {
+		policy.Error = httperrors.ErrForbidden
+	}
+	requiresPrincipal := len(policy.Roles) > 0 || len(policy.Scopes) > 0 || len(policy.Permissions) > 0
+
+	meta := map[string]any{}
+	if policy.Name != "" {
+		meta["policy"] = policy.Name
+	}
+	if len(policy.Roles) > 0 {
+		meta["roles"] = slices.Clone(policy.Roles)
+	}
+	if len(policy.Scopes) > 0 {
+		meta["scopes"] = slices.Clone(policy.Scopes)
+	}
+	if len(policy.Permissions) > 0 {
+		meta["permissions"] = slices.Clone(policy.Permissions)
+	}
+	if policy.Check != nil {
+		meta["check"] = true
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			principal := PrincipalFromContext(c)
+			if requiresPrincipal {
+				if principal == nil {
+					AbortWithError(c, httperrors.ErrUnauthorized)
+					return
+				}
+				if !policy.allows(principal) {
+					AbortWithError(c, policy.Error)
+					return
+				}
+			}
+			if policy.Check == nil {
+				return
+			}
+			if err := policy.Check(middlewareContext(c)); err != nil {
+				var httpErr *httperrors.Error
+				if !errors.As(err, &httpErr) {
+					_ = c.Error(err)
+					httpErr = policy.Error
+				}
+				AbortWithError(c, httpErr)
+			}
+		},
+		Meta: RouteMeta{"authorization": RouteMetaList{meta}},
+	}
+}
+
+// RequireRoles returns a middleware granting access to callers having any of
+// the roles.
+func RequireRoles(roles ...string) *Middleware {
+	return Authorize(AuthorizationPolicy{Roles: roles})
+}
+
+// RequireScopes returns a middleware granting access to callers having all of
+// the scopes.
+func RequireScopes(scopes ...string) *Middleware {
+	return Authorize(AuthorizationPolicy{Scopes: scopes})
+}
+
+// RequirePermissions returns a middleware granting access to callers having
+// all of the permissions.
+func RequirePermissions(permissions ...string) *Middleware {
+	return Authorize(AuthorizationPolicy{Permissions: permissions})
+}
+
+// RequireFunc returns a middleware granting access when check returns nil.
+// name identifies the check in the route metadata.
+func RequireFunc(name string, check func(c *Context) error) *Middleware {
+	return Authorize(AuthorizationPolicy{Name: name, Check: check})
+}
+
+func (p AuthorizationPolicy) allows(principal Principal) bool {
+	if len(p.Roles) > 0 && !slices.ContainsFunc(p.Roles, principal.HasRole) {
+		return false
+	}
+	for _, scope := range p.Scopes {
+		if !principal.HasScope(scope) {
+			return false
+		}
+	}
+	for _, permission := range p.Permissions {
+		if !principal.HasPermission(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// middlewareContext returns a Context for callbacks of gin middleware, which
+// run outside of a fox handler. Callbacks should return errors instead of
+// rendering.
+func middlewareContext(c *gin.Context) *Context {
+	return &Context{
+		Context: c,
+		engine:  engineFromContext(c),
+		Logger:  requestLogger(c),
+		Request: c.Request,
+		timing:  serverTimingFromContext(c),
+	}
+}
@@ -0,0 +1,135 @@
+package fox
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+func withPrincipal(principal Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal != nil {
+			SetPrincipal(c, principal)
+		}
+	}
+}
+
+func TestAuthorize_Requirements(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		principal Principal
+		policy    *Middleware
+		status    int
+	}{
+		{"no principal", nil, RequireRoles("admin"), http.StatusUnauthorized},
+		{"any role", Grants{Roles: []string{"editor"}}, RequireRoles("admin", "editor"), http.StatusOK},
+		{"missing role", Grants{Roles: []string{"viewer"}}, RequireRoles("admin"), http.StatusForbidden},
+		{"all scopes", Grants{Scopes: []string{"read", "write"}}, RequireScopes("read", "write"), http.StatusOK},
+		{"missing scope", Grants{Scopes: []string{"read"}}, RequireScopes("read", "write"), http.StatusForbidden},
+		{"permissions", Grants{Permissions: []string{"users:delete"}}, RequirePermissions("users:delete"), http.StatusOK},
+		{"missing permission", Grants{}, RequirePermissions("users:delete"), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New()
+			router.GET("/", withPrincipal(tt.principal), tt.policy, func() string { return "ok" })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestAuthorize_GroupAndRoutePoliciesMustAllPass(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	admin := router.Group("/admin", withPrincipal(Grants{Roles: []string{"admin"}}), RequireRoles("admin"))
+	admin.GET("/users", func() string { return "ok" })
+	admin.DELETE("/users", RequirePermissions("users:delete"), func() string { return "ok" })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/users", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "ACCESS_IS_FORBIDDEN")
+}
+
+func TestAuthorize_Check(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	errNotOwner := &httperrors.Error{HTTPCode: http.StatusForbidden, Err: errors.New("not owner"), Code: "NOT_OWNER"}
+
+	router := New()
+	router.GET("/posts/:owner", RequireFunc("owner", func(c *Context) error {
+		if c.Param("owner") != c.GetHeader("X-User") {
+			return errNotOwner
+		}
+		return nil
+	}), func() string { return "ok" })
+	router.GET("/plain", RequireFunc("plain", func(*Context) error {
+		return errors.New("denied")
+	}), func() string { return "ok" })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/posts/alice", nil)
+	req.Header.Set("X-User", "alice")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/posts/alice", nil)
+	req.Header.Set("X-User", "bob")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "NOT_OWNER")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "ACCESS_IS_FORBIDDEN")
+}
+
+func TestAuthorize_Manifest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authentication := &Middleware{
+		Handler: withPrincipal(Grants{Scopes: []string{"orders:read", "orders:write"}}),
+		Meta:    RouteMeta{"authentication": map[string]any{"scheme": "bearer"}},
+	}
+
+	router := New()
+	api := router.Group("/api", authentication, RequireScopes("orders:read"))
+	api.POST("/orders", Authorize(AuthorizationPolicy{
+		Name:   "writer",
+		Roles:  []string{"clerk"},
+		Scopes: []string{"orders:read", "orders:write"},
+	}), func() string { return "ok" })
+	router.GET("/public", func() string { return "ok" })
+
+	manifest := RouteManifestFromEngine(router)
+	routes := map[string]RouteManifestRoute{}
+	for _, route := range manifest.Routes {
+		routes[route.Path] = route
+	}
+
+	orders := routes["/api/orders"]
+	assert.Equal(t, RouteMetaList{
+		map[string]any{"scopes": []string{"orders:read"}},
+		map[string]any{"policy": "writer", "roles": []string{"clerk"}, "scopes": []string{"orders:read", "orders:write"}},
+	}, orders.Meta["authorization"])
+	assert.Equal(t, []map[string][]string{{"bearer": {"orders:read", "orders:write"}}}, orders.Security)
+	assert.Empty(t, routes["/public"].Security)
+}
@@ -2,6 +2,7 @@ package fox
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +20,10 @@ var ErrBindNonPointerValue = errors.New("can not bind to non-pointer value")
 // destination field type.
 var ErrBindContextTypeMismatch = errors.New("context value type mismatch")
 
+// ErrBindSessionTypeMismatch is returned when a session value bound via a
+// `session:"key"` tag cannot be converted to the destination field type.
+var ErrBindSessionTypeMismatch = errors.New("session value type mismatch")
+
 // DefaultBinder default binder
 var DefaultBinder binding.Binding = binding.JSON
 
@@ -40,7 +45,8 @@ var bodyBinders = map[string]binding.BindingBody{
 }
 
 // bind populates obj from the request: body (per Content-Type), then any
-// `context`, `query`, `uri`, and `header` tagged fields, in that order.
+// `context`, `session`, `query`, `uri`, and `header` tagged fields, in that
+// order.
 func bind(ctx *Context, obj any) error {
 	vPtr := reflect.ValueOf(obj)
 
@@ -50,48 +56,13 @@ func bind(ctx *Context, obj any) error {
 
 	// bind request body
 	// --------------------------------------------------------------------------
-	var (
-		contentType = filterFlags(ctx.Request.Header.Get("Content-Type"))
-		body        []byte
-		err         error
-	)
-
-	shouldReadBody := ctx.Request.ContentLength != 0 ||
-		len(ctx.Request.TransferEncoding) > 0
-	if shouldReadBody {
-		if body, err = ctx.RequestBody(); err != nil {
-			return err
-		}
-
-		defer func() {
-			// copy the request body to the next handler
-			ctx.Request.Body = io.NopCloser(bytes.NewBuffer(body))
-		}()
-	}
-
-	if ctx.Request.Method == http.MethodGet {
-		err = binding.Form.Bind(ctx.Request, obj)
-	} else if binder, exists := binders[contentType]; exists {
-		err = binder.Bind(ctx.Request, obj)
-	} else if bodyBinder, exists := bodyBinders[contentType]; exists {
-		if len(body) > 0 {
-			err = bodyBinder.BindBody(body, obj)
-		}
-	} else if DefaultBinder != nil {
-		if bodyBinder, ok := DefaultBinder.(binding.BindingBody); ok {
-			if len(body) > 0 {
-				err = bodyBinder.BindBody(body, obj)
-			}
-		} else {
-			err = DefaultBinder.Bind(ctx.Request, obj)
-		}
-	}
-	if err != nil {
+	if err := bindBody(ctx, obj, ctx.Request.Method == http.MethodGet); err != nil {
 		return err
 	}
 
 	// bind request query, header and uri
 	// --------------------------------------------------------------------------
+	var err error
 	vPtr = vPtr.Elem()
 
 	for vPtr.Kind() == reflect.Ptr {
@@ -125,6 +96,11 @@ func bind(ctx *Context, obj any) error {
 				return err
 			}
 		}
+		if tag := field.Tag.Get("session"); tag != "" && tag != "-" {
+			if err := bindSessionField(ctx, vPtr.Field(i), field.Name, tag); err != nil {
+				return err
+			}
+		}
 	}
 
 	// bind query params
@@ -136,11 +112,7 @@ func bind(ctx *Context, obj any) error {
 
 	// bind uri path
 	if hasURIField && len(ctx.Params) > 0 {
-		m := make(map[string][]string)
-		for _, v := range ctx.Params {
-			m[v.Key] = []string{v.Value}
-		}
-		if err = binding.Uri.BindUri(m, obj); err != nil {
+		if err = bindPath(ctx, obj); err != nil {
 			return err
 		}
 	}
@@ -152,18 +124,201 @@ func bind(ctx *Context, obj any) error {
 		}
 	}
 
+	return validBound(ctx, obj, vPtr)
+}
+
+// bindBody binds the request body per Content-Type, or the query form when
+// queryForm is set.
+func bindBody(ctx *Context, obj any, queryForm bool) error {
+	var (
+		contentType = filterFlags(ctx.Request.Header.Get("Content-Type"))
+		body        []byte
+		err         error
+	)
+
+	shouldReadBody := ctx.Request.ContentLength != 0 ||
+		len(ctx.Request.TransferEncoding) > 0
+	if shouldReadBody {
+		if body, err = ctx.RequestBody(); err != nil {
+			return err
+		}
+
+		defer func() {
+			// copy the request body to the next handler
+			ctx.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}()
+	}
+
+	if queryForm {
+		err = binding.Form.Bind(ctx.Request, obj)
+	} else if binder, exists := binders[contentType]; exists {
+		err = binder.Bind(ctx.Request, obj)
+	} else if bodyBinder, exists := bodyBinders[contentType]; exists {
+		if len(body) > 0 {
+			err = bodyBinder.BindBody(body, obj)
+		}
+	} else if DefaultBinder != nil {
+		if bodyBinder, ok := DefaultBinder.(binding.BindingBody); ok {
+			if len(body) > 0 {
+				err = bodyBinder.BindBody(body, obj)
+			}
+		} else {
+			err = DefaultBinder.Bind(ctx.Request, obj)
+		}
+	}
+	return err
+}
+
+// bindPath binds the `uri` tagged fields from the route params.
+func bindPath(ctx *Context, obj any) error {
+	m := make(map[string][]string, len(ctx.Params))
+	for _, v := range ctx.Params {
+		m[v.Key] = []string{v.Value}
+	}
+	return binding.Uri.BindUri(m, obj)
+}
+
+// boundCompleter is implemented by bound types completed from the request
+// context once bound, such as Page.
+type boundCompleter interface {
+	completeBound(ctx *Context) error
+}
+
+// validBound completes a bound value and calls its IsValid method, v being
+// obj dereferenced.
+func validBound(ctx *Context, obj any, v reflect.Value) error {
+	if v.CanAddr() {
+		if completer, ok := v.Addr().Interface().(boundCompleter); ok {
+			if err := completer.completeBound(ctx); err != nil {
+				return err
+			}
+		}
+	}
 	if valider, ok := obj.(IsValider); ok {
 		return valider.IsValid()
 	}
-	if vPtr.CanAddr() {
-		if valider, ok := vPtr.Addr().Interface().(IsValider); ok {
+	if v.CanAddr() {
+		if valider, ok := v.Addr().Interface().(IsValider); ok {
 			return valider.IsValid()
 		}
 	}
-
 	return nil
 }
 
+// BindingSource is the single part of the request a handler parameter is
+// bound from, see BindingSourcer.
+type BindingSource string
+
+const (
+	// SourcePath binds the `uri` tagged fields from the route params.
+	SourcePath BindingSource = "path"
+
+	// SourceQuery binds the `query` tagged fields from the URL query.
+	SourceQuery BindingSource = "query"
+
+	// SourceHeader binds the `header` tagged fields from the request headers.
+	SourceHeader BindingSource = "header"
+
+	// SourceBody binds the request body per Content-Type.
+	SourceBody BindingSource = "body"
+)
+
+// BindingSourcer is implemented by handler parameter types bound from a
+// single source. Handlers may take any number of such parameters, besides
+// one parameter bound from all sources:
+//
+//	type UserPath struct {
+//		fox.FromPath
+//		ID int64 `uri:"id" binding:"required"`
+//	}
+//
+//	type ListQuery struct {
+//		fox.FromQuery
+//		Limit int `query:"limit"`
+//	}
+//
+//	router.PATCH("/users/:id", func(c *fox.Context, path UserPath, q ListQuery, body UpdateUser) (*User, error) {
+//		...
+//	})
+//
+// Embedding FromPath, FromQuery, FromHeader or FromBody implements it. The
+// source is recorded in the parameters of the route manifest.
+type BindingSourcer interface {
+	BindingSource() BindingSource
+}
+
+// FromPath marks a parameter struct bound from the route params.
+type FromPath struct{}
+
+// BindingSource implements BindingSourcer.
+func (FromPath) BindingSource() BindingSource { return SourcePath }
+
+// FromQuery marks a parameter struct bound from the URL query.
+type FromQuery struct{}
+
+// BindingSource implements BindingSourcer.
+func (FromQuery) BindingSource() BindingSource { return SourceQuery }
+
+// FromHeader marks a parameter struct bound from the request headers.
+type FromHeader struct{}
+
+// BindingSource implements BindingSourcer.
+func (FromHeader) BindingSource() BindingSource { return SourceHeader }
+
+// FromBody marks a parameter struct bound from the request body.
+type FromBody struct{}
+
+// BindingSource implements BindingSourcer.
+func (FromBody) BindingSource() BindingSource { return SourceBody }
+
+// bindingSourceOf returns the source of a parameter type implementing
+// BindingSourcer, or "".
+func bindingSourceOf(typ reflect.Type) BindingSource {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if sourcer, ok := reflect.New(typ).Interface().(BindingSourcer); ok {
+		return sourcer.BindingSource()
+	}
+	return ""
+}
+
+// bindSource populates obj from a single source.
+func bindSource(ctx *Context, obj any, source BindingSource) error {
+	vPtr := reflect.ValueOf(obj)
+	if vPtr.Kind() != reflect.Ptr {
+		return ErrBindNonPointerValue
+	}
+
+	// Bind pointer parameters through their innermost pointer, so the
+	// validator sees the struct.
+	for vPtr.Elem().Kind() == reflect.Ptr {
+		if vPtr.Elem().IsNil() {
+			vPtr.Elem().Set(reflect.New(vPtr.Elem().Type().Elem()))
+		}
+		vPtr = vPtr.Elem()
+	}
+	obj = vPtr.Interface()
+
+	var err error
+	switch source {
+	case SourcePath:
+		err = bindPath(ctx, obj)
+	case SourceQuery:
+		err = Query.Bind(ctx.Request, obj)
+	case SourceHeader:
+		err = binding.Header.Bind(ctx.Request, obj)
+	case SourceBody:
+		err = bindBody(ctx, obj, false)
+	default:
+		err = fmt.Errorf("unknown binding source %q", source)
+	}
+	if err != nil {
+		return err
+	}
+	return validBound(ctx, obj, vPtr.Elem())
+}
+
 // bindContextField copies a value stored on ctx into a struct field tagged
 // with `context:"key"`. Missing keys, unexported fields and nil values are
 // no-ops; an unconvertible stored type returns ErrBindContextTypeMismatch.
@@ -184,6 +339,36 @@ func bindContextField(ctx *Context, fieldValue reflect.Value, fieldName, key str
 	return nil
 }
 
+// bindSessionField copies a session value into a struct field tagged with
+// `session:"key"`. Values that went through a serializing SessionStore, such
+// as a struct stored as a JSON object, are decoded into the field type.
+// Missing sessions, keys and unexported fields are no-ops.
+func bindSessionField(ctx *Context, fieldValue reflect.Value, fieldName, key string) error {
+	session := ctx.Session()
+	if session == nil {
+		return nil
+	}
+	value := session.Get(key)
+	if value == nil || !fieldValue.CanSet() {
+		return nil
+	}
+	val := reflect.ValueOf(value)
+	if val.Type().ConvertibleTo(fieldValue.Type()) {
+		fieldValue.Set(val.Convert(fieldValue.Type()))
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err == nil {
+		target := reflect.New(fieldValue.Type())
+		if err = json.Unmarshal(data, target.Interface()); err == nil {
+			fieldValue.Set(target.Elem())
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: key %q (%T) -> field %s (%s)",
+		ErrBindSessionTypeMismatch, key, value, fieldName, fieldValue.Type())
+}
+
 type queryBinding struct{}
 
 func (queryBinding) Name() string {
@@ -0,0 +1,135 @@
+package fox
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOrderPath struct {
+	FromPath
+	ID int `uri:"id" validate:"required"`
+}
+
+type testOrderQuery struct {
+	FromQuery
+	Expand string `query:"expand"`
+	Limit  int    `query:"limit" validate:"max=100"`
+}
+
+type testOrderHeader struct {
+	FromHeader
+	Tenant string `header:"X-Tenant" validate:"required"`
+}
+
+type testOrderBody struct {
+	FromBody
+	Note string `json:"note"`
+	// Query fields of body structs are not bound.
+	Expand string `json:"-" query:"expand"`
+}
+
+// testOrderFilter is bound from the query through the interface rather than
+// marker embedding.
+type testOrderFilter struct {
+	Status string `query:"status"`
+}
+
+func (testOrderFilter) BindingSource() BindingSource { return SourceQuery }
+
+type testOrderArgs struct {
+	ID     int    `uri:"id"`
+	Expand string `query:"expand"`
+}
+
+func TestBindSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.PUT("/orders/:id", func(c *Context, path testOrderPath, query *testOrderQuery, header testOrderHeader, body testOrderBody) map[string]any {
+		return map[string]any{
+			"id":          path.ID,
+			"expand":      query.Expand,
+			"tenant":      header.Tenant,
+			"note":        body.Note,
+			"body_expand": body.Expand,
+		}
+	})
+
+	t.Run("all sources", func(t *testing.T) {
+		w := serve(router, http.MethodPut, "/orders/7?expand=items", `{"note":"rush"}`, "X-Tenant", "acme")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, map[string]any{
+			"id":          float64(7),
+			"expand":      "items",
+			"tenant":      "acme",
+			"note":        "rush",
+			"body_expand": "",
+		}, res)
+	})
+
+	t.Run("validation of each source", func(t *testing.T) {
+		w := serve(router, http.MethodPut, "/orders/7", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Tenant")
+
+		w = serve(router, http.MethodPut, "/orders/7?limit=500", `{}`, "X-Tenant", "acme")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Limit")
+	})
+}
+
+func TestBindSource_WithRequestArgs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/orders/:id", func(c *Context, filter testOrderFilter, args testOrderArgs) map[string]any {
+		return map[string]any{"status": filter.Status, "id": args.ID, "expand": args.Expand}
+	})
+
+	w := serve(router, http.MethodGet, "/orders/3?expand=items&status=open", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"status":"open","id":3,"expand":"items"}`, w.Body.String())
+}
+
+func TestBindSource_HandlerValidation(t *testing.T) {
+	router := New()
+
+	assert.True(t, IsValidHandlerFunc(func(c *Context, path testOrderPath, query testOrderQuery, body testOrderBody) {}))
+	assert.True(t, IsValidHandlerFunc(func(c *Context, path *testOrderPath, args testOrderArgs) {}))
+	assert.False(t, IsValidHandlerFunc(func(c *Context, a testOrderArgs, b testOrderArgs) {}))
+
+	assert.Panics(t, func() {
+		router.GET("/two", func(c *Context, path testOrderPath, a testOrderArgs, b testOrderArgs) string { return "" })
+	})
+}
+
+func TestBindSource_RouteManifest(t *testing.T) {
+	router := New()
+	router.ProvideValue(&testDB{})
+	router.PUT("/orders/:id", func(c *Context, db *testDB, path testOrderPath, header testOrderHeader, args testOrderArgs) string {
+		return ""
+	})
+	router.POST("/orders", func(c *Context, args testOrderArgs) string { return "" })
+
+	manifest := RouteManifestFromEngine(router)
+	require.Len(t, manifest.Routes, 2)
+
+	assert.Empty(t, manifest.Routes[0].Parameters)
+
+	params := manifest.Routes[1].Parameters
+	require.Len(t, params, 3)
+	assert.Equal(t, "path", params[0].Source)
+	assert.Equal(t, "testOrderPath", params[0].Type.Name)
+	assert.Equal(t, "header", params[1].Source)
+	assert.Equal(t, "testOrderHeader", params[1].Type.Name)
+	assert.Equal(t, "request", params[2].Source)
+	assert.Equal(t, "testOrderArgs", params[2].Type.Name)
+}
@@ -0,0 +1,148 @@
+package fox
+
+import (
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// DefaultMaxBodySize is the default Engine.MaxBodySize, 32MB.
+const DefaultMaxBodySize = 32 << 20
+
+// bodyLimitContextKey stores the *limitedBody of the current request.
+const bodyLimitContextKey = "_fox-gonic/fox/body-limit"
+
+// BodyLimitConfig defines the config for BodyLimit middleware.
+type BodyLimitConfig struct {
+	// MaxSize is the maximum request body size in bytes. Zero keeps the
+	// limit of the group or engine.
+	// Optional.
+	MaxSize int64
+
+	// ContentTypes sets the maximum size per media type, for example
+	// {"application/json": 1 << 20, "multipart/form-data": 50 << 20}, and
+	// takes precedence over MaxSize.
+	// Optional.
+	ContentTypes map[string]int64
+}
+
+// BodyLimit returns a middleware that bounds the request body of the routes
+// it is attached to, overriding Engine.MaxBodySize and the limits of outer
+// groups, including for fox middleware that run before it. fox route handlers
+// reject requests declaring a larger Content-Length with 413
+// httperrors.ErrRequestEntityTooLarge before running; bodies that turn
+// out to be larger fail Context.RequestBody, binding and multipart parsing
+// with the same error. The limits are recorded as the "maxBodySize" and
+// "maxBodySizeByContentType" route metadata.
+//
+//	api := router.Group("/api", fox.BodyLimit(fox.BodyLimitConfig{MaxSize: 1 << 20}))
+//	api.POST("/upload", fox.BodyLimit(fox.BodyLimitConfig{
+//		ContentTypes: map[string]int64{"multipart/form-data": 50 << 20},
+//	}), Upload)
+func BodyLimit(config BodyLimitConfig) *Middleware {
+	meta := RouteMeta{}
+	if config.MaxSize > 0 {
+		meta["maxBodySize"] = config.MaxSize
+	}
+	if len(config.ContentTypes) > 0 {
+		meta["maxBodySizeByContentType"] = maps.Clone(config.ContentTypes)
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			if limit := bodyLimitFor(c, config.MaxSize, config.ContentTypes); limit > 0 {
+				limitRequestBody(c, limit, true)
+			}
+		},
+		Meta: meta,
+	}
+}
+
+// limitBody bounds the request body by the engine limits unless a BodyLimit
+// middleware already did. Later BodyLimit middleware replace the limit.
+func limitBody(c *gin.Context, engine *Engine) {
+	if _, exists := c.Get(bodyLimitContextKey); !exists && engine != nil {
+		if limit := bodyLimitFor(c, engine.MaxBodySize, engine.MaxBodySizeByContentType); limit > 0 {
+			limitRequestBody(c, limit, false)
+		}
+	}
+}
+
+// checkBodyLimit is limitBody also rejecting requests whose declared
+// Content-Length exceeds the limit, once every BodyLimit has run.
+func checkBodyLimit(c *gin.Context, engine *Engine) error {
+	limitBody(c, engine)
+	if v, exists := c.Get(bodyLimitContextKey); exists {
+		if body, ok := v.(*limitedBody); ok && c.Request.ContentLength > body.limit {
+			return httperrors.ErrRequestEntityTooLarge
+		}
+	}
+	return nil
+}
+
+// bodyLimitFor returns the limit for the request media type.
+func bodyLimitFor(c *gin.Context, maxSize int64, contentTypes map[string]int64) int64 {
+	if len(contentTypes) > 0 {
+		mediaType := strings.ToLower(filterFlags(c.GetHeader("Content-Type")))
+		if limit, ok := contentTypes[mediaType]; ok {
+			return limit
+		}
+	}
+	return maxSize
+}
+
+// limitRequestBody bounds the request body to limit bytes. The body is
+// wrapped once; later calls with override adjust the limit, so that a route
+// limit replaces the one of its group.
+func limitRequestBody(c *gin.Context, limit int64, override bool) {
+	if v, exists := c.Get(bodyLimitContextKey); exists {
+		if body, ok := v.(*limitedBody); ok && override {
+			body.limit = limit
+		}
+		return
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return
+	}
+	body := &limitedBody{ReadCloser: c.Request.Body, limit: limit}
+	c.Request.Body = body
+	c.Set(bodyLimitContextKey, body)
+}
+
+// limitedBody fails with httperrors.ErrRequestEntityTooLarge once more than
+// limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+	err   error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	remaining := b.limit - b.read
+	if remaining < 0 {
+		remaining = 0
+	}
+	// Read one byte more than allowed to tell a body of exactly the limit
+	// from a larger one.
+	if int64(len(p)) > remaining+1 {
+		p = p[:remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if b.read+int64(n) > b.limit {
+		n = int(remaining)
+		b.read = b.limit
+		b.err = httperrors.ErrRequestEntityTooLarge
+		return n, b.err
+	}
+	b.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,186 @@
+package fox
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+type bodyLimitPayload struct {
+	Name string `json:"name" form:"name"`
+}
+
+func bodyLimitRequest(router http.Handler, path, contentType string, body []byte, chunked bool) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	var reader io.Reader = bytes.NewReader(body)
+	if chunked {
+		// Hide the length so that the limit is enforced while reading.
+		reader = io.MultiReader(reader)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, reader)
+	if chunked {
+		req.ContentLength = -1
+	}
+	req.Header.Set("Content-Type", contentType)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func jsonName(n int) []byte {
+	return []byte(`{"name":"` + strings.Repeat("a", n) + `"}`)
+}
+
+func TestBodyLimit_EngineDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	assert.Equal(t, int64(DefaultMaxBodySize), router.MaxBodySize)
+	router.MaxBodySize = 64
+	router.POST("/echo", func(c *Context, p *bodyLimitPayload) string { return p.Name })
+
+	w := bodyLimitRequest(router, "/echo", "application/json", jsonName(10), false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strings.Repeat("a", 10), w.Body.String())
+
+	w = bodyLimitRequest(router, "/echo", "application/json", jsonName(100), false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "REQUEST_ENTITY_TOO_LARGE")
+
+	w = bodyLimitRequest(router, "/echo", "application/json", jsonName(100), true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Exactly at the limit is allowed.
+	w = bodyLimitRequest(router, "/echo", "application/json", jsonName(64-len(`{"name":""}`)), true)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBodyLimit_GroupAndRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	api := router.Group("/api", BodyLimit(BodyLimitConfig{MaxSize: 32}))
+	api.POST("/small", func(c *Context, p *bodyLimitPayload) string { return p.Name })
+	api.POST("/large", BodyLimit(BodyLimitConfig{MaxSize: 256}), func(c *Context, p *bodyLimitPayload) string {
+		return p.Name
+	})
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge,
+		bodyLimitRequest(router, "/api/small", "application/json", jsonName(100), false).Code)
+	assert.Equal(t, http.StatusOK,
+		bodyLimitRequest(router, "/api/large", "application/json", jsonName(100), false).Code)
+	assert.Equal(t, http.StatusOK,
+		bodyLimitRequest(router, "/api/large", "application/json", jsonName(100), true).Code)
+
+	meta := map[string]RouteMeta{}
+	for _, route := range router.HandlerRoutes() {
+		meta[route.Path] = route.Meta
+	}
+	assert.Equal(t, int64(32), meta["/api/small"]["maxBodySize"])
+	assert.Equal(t, int64(256), meta["/api/large"]["maxBodySize"])
+}
+
+func TestBodyLimit_AfterMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.MaxBodySize = 10
+	router.Use(func(c *Context) {})
+	router.POST("/large", BodyLimit(BodyLimitConfig{MaxSize: 100}), func(c *Context, p *bodyLimitPayload) string {
+		return p.Name
+	})
+	router.POST("/small", func(c *Context, p *bodyLimitPayload) string { return p.Name })
+
+	// Fox middleware leave the rejection to the route handler, after the
+	// route raised the limit.
+	for _, chunked := range []bool{false, true} {
+		assert.Equal(t, http.StatusOK,
+			bodyLimitRequest(router, "/large", "application/json", jsonName(40), chunked).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge,
+			bodyLimitRequest(router, "/small", "application/json", jsonName(40), chunked).Code)
+	}
+}
+
+func TestBodyLimit_ContentTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.POST("/upload", BodyLimit(BodyLimitConfig{
+		MaxSize: 1 << 20,
+		ContentTypes: map[string]int64{
+			"application/json":    32,
+			"multipart/form-data": 1 << 10,
+		},
+	}), func(c *Context, p *bodyLimitPayload) string { return p.Name })
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge,
+		bodyLimitRequest(router, "/upload", "application/json; charset=utf-8", jsonName(100), false).Code)
+
+	multipartBody := func(n int) ([]byte, string) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		require.NoError(t, mw.WriteField("name", strings.Repeat("a", n)))
+		require.NoError(t, mw.Close())
+		return buf.Bytes(), mw.FormDataContentType()
+	}
+
+	body, contentType := multipartBody(100)
+	w := bodyLimitRequest(router, "/upload", contentType, body, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strings.Repeat("a", 100), w.Body.String())
+
+	body, contentType = multipartBody(4 << 10)
+	assert.Equal(t, http.StatusRequestEntityTooLarge,
+		bodyLimitRequest(router, "/upload", contentType, body, true).Code)
+
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, map[string]int64{"application/json": 32, "multipart/form-data": 1 << 10},
+		routes[0].Meta["maxBodySizeByContentType"])
+}
+
+func TestBodyLimit_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.MaxBodySize = 0
+	router.POST("/echo", func(c *Context, p *bodyLimitPayload) string { return p.Name })
+
+	w := bodyLimitRequest(router, "/echo", "application/json", jsonName(1000), true)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestContext_RequestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789"))
+	c.Request.ContentLength = -1
+
+	router := New()
+	router.MaxBodySize = 4
+	ctx := &Context{Context: c, engine: router, Request: c.Request}
+	_, err := ctx.RequestBody()
+	require.ErrorIs(t, err, httperrors.ErrRequestEntityTooLarge)
+}
+
+func TestLimitedBody(t *testing.T) {
+	b := &limitedBody{ReadCloser: io.NopCloser(strings.NewReader("12345")), limit: 5}
+	data, err := io.ReadAll(b)
+	require.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	b = &limitedBody{ReadCloser: io.NopCloser(strings.NewReader("123456")), limit: 5}
+	data, err = io.ReadAll(b)
+	require.ErrorIs(t, err, httperrors.ErrRequestEntityTooLarge)
+	assert.Equal(t, "12345", string(data))
+}
@@ -0,0 +1,350 @@
+package fox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cacheTagsContextKey stores the tags added by Context.CacheTags.
+const cacheTagsContextKey = "_fox-gonic/fox/cache/tags"
+
+// CacheConfig defines the config for Cache middleware.
+type CacheConfig struct {
+	// CacheControl is set as the Cache-Control header of successful responses
+	// unless the handler sets one, for example "public, max-age=60".
+	// Optional.
+	CacheControl string
+
+	// WeakETag generates weak (W/"...") instead of strong ETags.
+	// Optional.
+	WeakETag bool
+
+	// Store enables server-side caching of successful responses. A store can
+	// be shared by several routes; entries are keyed by route template, path
+	// parameters, query and the Vary request headers.
+	// Optional.
+	Store *ResponseCache
+
+	// TTL is how long responses are kept in Store. Zero keeps them until they
+	// are evicted or invalidated.
+	// Optional.
+	TTL time.Duration
+
+	// Vary lists the request headers that select a different representation,
+	// for example Accept-Language. They are part of the Store key and sent
+	// in the Vary response header.
+	// Optional.
+	Vary []string
+}
+
+// Cache returns a middleware for GET and HEAD handlers that computes an ETag
+// from the rendered body, answers If-None-Match and If-Modified-Since
+// requests with 304 Not Modified, and sets the configured Cache-Control
+// header. Handlers may set their own ETag or Last-Modified header, which take
+// precedence.
+//
+// With a Store, successful responses are also served from memory without
+// running the handler; the X-Cache response header reports HIT or MISS.
+// Handlers tag responses with Context.CacheTags and drop them later with
+// ResponseCache.Invalidate. Responses with Cache-Control no-store or private
+// are not stored. The config is recorded as the "cache" route metadata.
+//
+//	store := fox.NewResponseCache()
+//	router.GET("/users/:id", fox.Cache(fox.CacheConfig{
+//		CacheControl: "public, max-age=60",
+//		Store:        store,
+//		TTL:          time.Minute,
+//	}), GetUser)
+//	router.PUT("/users/:id", func(c *fox.Context) error {
+//		...
+//		store.Invalidate("user:" + c.Param("id"))
+//		return nil
+//	})
+func Cache(config CacheConfig) *Middleware {
+	vary := strings.Join(config.Vary, ", ")
+
+	meta := map[string]any{}
+	if config.CacheControl != "" {
+		meta["cacheControl"] = config.CacheControl
+	}
+	if config.Store != nil {
+		meta["server"] = true
+		if config.TTL > 0 {
+			meta["ttl"] = config.TTL.String()
+		}
+	}
+	if len(config.Vary) > 0 {
+		meta["vary"] = slices.Clone(config.Vary)
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			method := c.Request.Method
+			if method != http.MethodGet && method != http.MethodHead {
+				return
+			}
+
+			var key string
+			if config.Store != nil && method == http.MethodGet {
+				key = responseCacheKey(c, config.Vary)
+				if entry := config.Store.get(key); entry != nil {
+					header := c.Writer.Header()
+					maps.Copy(header, entry.header)
+					header.Set("X-Cache", "HIT")
+					writeConditional(c.Writer, c.Request, entry.status, entry.body)
+					c.Abort()
+					return
+				}
+			}
+
+			w := &cacheWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = w
+			before := w.Header().Clone()
+			c.Next()
+			c.Writer = w.ResponseWriter
+
+			if w.passthrough {
+				return
+			}
+
+			header := w.Header()
+			status := w.status
+			if status == http.StatusOK {
+				if header.Get("ETag") == "" {
+					header.Set("ETag", computeETag(w.body.Bytes(), config.WeakETag))
+				}
+				if config.CacheControl != "" && header.Get("Cache-Control") == "" {
+					header.Set("Cache-Control", config.CacheControl)
+				}
+				if vary != "" {
+					header.Add("Vary", vary)
+				}
+			}
+
+			if key != "" {
+				if status == http.StatusOK && storableResponse(header) {
+					config.Store.set(&cachedResponse{
+						key:    key,
+						status: status,
+						header: handlerHeader(before, header),
+						body:   bytes.Clone(w.body.Bytes()),
+						tags:   c.GetStringSlice(cacheTagsContextKey),
+					}, config.TTL)
+				}
+				header.Set("X-Cache", "MISS")
+			}
+
+			if status == http.StatusOK {
+				writeConditional(w.ResponseWriter, c.Request, status, w.body.Bytes())
+				return
+			}
+			w.flush()
+		},
+		Meta: RouteMeta{"cache": meta},
+	}
+}
+
+// CacheTags tags the response for server-side caching by the Cache
+// middleware, so that it can be dropped with ResponseCache.Invalidate.
+func (c *Context) CacheTags(tags ...string) {
+	existing := c.GetStringSlice(cacheTagsContextKey)
+	c.Set(cacheTagsContextKey, append(slices.Clone(existing), tags...))
+}
+
+// responseCacheKey identifies a response by route template, path parameters,
+// query and the values of the vary request headers.
+func responseCacheKey(c *gin.Context, vary []string) string {
+	var b strings.Builder
+	b.WriteString(c.Request.Host)
+	b.WriteByte(0)
+	b.WriteString(c.FullPath())
+	for _, param := range c.Params {
+		b.WriteByte(0)
+		b.WriteString(param.Key)
+		b.WriteByte('=')
+		b.WriteString(param.Value)
+	}
+	b.WriteByte(0)
+	b.WriteString(c.Request.URL.Query().Encode()) // sorted by key
+	for _, name := range vary {
+		b.WriteByte(0)
+		b.WriteString(strings.Join(c.Request.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// computeETag returns a quoted ETag derived from the SHA-256 of body.
+func computeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+func storableResponse(header http.Header) bool {
+	for directive := range strings.SplitSeq(strings.ToLower(header.Get("Cache-Control")), ",") {
+		switch strings.TrimSpace(directive) {
+		case "no-store", "private":
+			return false
+		}
+	}
+	return header.Get("Set-Cookie") == ""
+}
+
+// writeConditional writes a successful response, or 304 Not Modified when the
+// conditional headers of req match it.
+func writeConditional(w gin.ResponseWriter, req *http.Request, status int, body []byte) {
+	if notModified(req, w.Header()) {
+		header := w.Header()
+		for _, name := range []string{"Content-Type", "Content-Length"} {
+			header.Del(name)
+		}
+		w.WriteHeader(http.StatusNotModified)
+		w.WriteHeaderNow()
+		return
+	}
+	w.WriteHeader(status)
+	if req.Method == http.MethodHead || len(body) == 0 {
+		w.WriteHeaderNow()
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when no
+// If-None-Match is sent, as specified by RFC 9110 section 13.2.2.
+func notModified(req *http.Request, header http.Header) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for candidate := range strings.SplitSeq(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETagMatch(candidate, etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := req.Header.Get("If-Modified-Since")
+	lastModified := header.Get("Last-Modified")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+func weakETagMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// cacheWriter buffers a response so that its ETag can be computed before it
+// is sent. Flushing or hijacking switches it to pass through.
+type cacheWriter struct {
+	gin.ResponseWriter
+
+	body        bytes.Buffer
+	status      int
+	written     bool
+	passthrough bool
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *cacheWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *cacheWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *cacheWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *cacheWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+func (w *cacheWriter) Flush() {
+	w.flush()
+	w.ResponseWriter.Flush()
+}
+
+func (w *cacheWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	return w.ResponseWriter.Hijack()
+}
+
+// flush sends the buffered response and switches to pass through.
+func (w *cacheWriter) flush() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	} else if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
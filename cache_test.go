@@ -0,0 +1,188 @@
+package fox
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_ETagAndConditional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/doc", Cache(CacheConfig{CacheControl: "public, max-age=60"}), func() string {
+		return "hello"
+	})
+	router.GET("/weak", Cache(CacheConfig{WeakETag: true}), func() string {
+		return "hello"
+	})
+
+	w := serve(router, http.MethodGet, "/doc", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, computeETag([]byte("hello"), false), etag)
+
+	w = serve(router, http.MethodGet, "/doc", "", "If-None-Match", `"other", `+etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = serve(router, http.MethodGet, "/doc", "", "If-None-Match", `"other"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+
+	w = serve(router, http.MethodGet, "/weak", "")
+	weak := w.Header().Get("ETag")
+	assert.Equal(t, "W/"+etag, weak)
+	w = serve(router, http.MethodGet, "/weak", "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestCache_IfModifiedSince(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	modified := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := New()
+	router.GET("/doc", Cache(CacheConfig{}), func(c *Context) string {
+		c.Header("Last-Modified", modified.Format(http.TimeFormat))
+		return "hello"
+	})
+
+	w := serve(router, http.MethodGet, "/doc", "", "If-Modified-Since", modified.Format(http.TimeFormat))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serve(router, http.MethodGet, "/doc", "", "If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+}
+
+func TestCache_ErrorsPassThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/missing", Cache(CacheConfig{CacheControl: "public"}), func(c *Context) {
+		c.String(http.StatusNotFound, "missing")
+	})
+
+	w := serve(router, http.MethodGet, "/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "missing", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+func TestCache_Store(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	store := NewResponseCache()
+	router := New()
+	router.GET("/users/:id", Cache(CacheConfig{Store: store, Vary: []string{"Accept-Language"}}), func(c *Context) string {
+		calls.Add(1)
+		c.CacheTags("user:" + c.Param("id"))
+		c.Header("X-Lang", c.GetHeader("Accept-Language"))
+		return "user " + c.Param("id")
+	})
+	router.PUT("/users/:id", func(c *Context) string {
+		store.Invalidate("user:" + c.Param("id"))
+		return "updated"
+	})
+
+	w := serve(router, http.MethodGet, "/users/1?b=2&a=1", "")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+	// Query order does not matter.
+	w = serve(router, http.MethodGet, "/users/1?a=1&b=2", "")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "user 1", w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	etag := w.Header().Get("ETag")
+	w = serve(router, http.MethodGet, "/users/1?a=1&b=2", "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Different params and Vary headers are cached separately.
+	serve(router, http.MethodGet, "/users/2", "")
+	w = serve(router, http.MethodGet, "/users/1?a=1&b=2", "", "Accept-Language", "fr")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, "fr", w.Header().Get("X-Lang"))
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 3, store.Len())
+
+	serve(router, http.MethodPut, "/users/1", "")
+	assert.Equal(t, 1, store.Len())
+	w = serve(router, http.MethodGet, "/users/1?a=1&b=2", "")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+}
+
+func TestCache_StoreSkipsPrivate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := NewResponseCache()
+	router := New()
+	router.GET("/me", Cache(CacheConfig{Store: store}), func(c *Context) string {
+		c.Header("Cache-Control", "private, max-age=10")
+		return "me"
+	})
+
+	serve(router, http.MethodGet, "/me", "")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestResponseCache_LRUAndTTL(t *testing.T) {
+	clock := newFakeClock()
+	store := NewResponseCache(30)
+	store.now = clock.Now
+
+	store.set(&cachedResponse{key: "a", body: []byte("0123456789")}, 0)
+	store.set(&cachedResponse{key: "b", body: []byte("0123456789")}, time.Second)
+	require.NotNil(t, store.get("a"))
+
+	// "b" is the least recently used and is evicted.
+	store.set(&cachedResponse{key: "c", body: []byte("0123456789")}, 0)
+	assert.Nil(t, store.get("b"))
+	assert.NotNil(t, store.get("a"))
+	assert.Equal(t, 22, store.Size())
+
+	// Responses larger than the cache are not stored.
+	store.set(&cachedResponse{key: "d", body: make([]byte, 40)}, 0)
+	assert.Nil(t, store.get("d"))
+
+	store.set(&cachedResponse{key: "e", body: []byte("x")}, time.Second)
+	clock.Advance(time.Second)
+	assert.Nil(t, store.get("e"))
+
+	store.Purge()
+	assert.Equal(t, 0, store.Len())
+	assert.Equal(t, 0, store.Size())
+}
+
+func TestCache_Meta(t *testing.T) {
+	router := New()
+	router.GET("/doc", Cache(CacheConfig{
+		CacheControl: "no-cache",
+		Store:        NewResponseCache(),
+		TTL:          time.Minute,
+		Vary:         []string{"Accept"},
+	}), func() string { return "doc" })
+
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, map[string]any{
+		"cacheControl": "no-cache",
+		"server":       true,
+		"ttl":          "1m0s",
+		"vary":         []string{"Accept"},
+	}, routes[0].Meta["cache"])
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/fox-gonic/fox/httperrors"
 )
@@ -20,20 +21,47 @@ func call(ctx *Context, handler HandlerFunc) any {
 		numOut = funcType.NumOut()
 	)
 
-	var values []reflect.Value
+	// Transport-agnostic handlers receive the request context.
+	if numIn > 0 && funcType.In(0) == stdContextType {
+		ctxValue = reflect.ValueOf(ctx.requestContext())
+	}
+
+	var in []reflect.Value
 
 	switch numIn {
 	case 0:
-		values = funcValue.Call([]reflect.Value{})
+		in = []reflect.Value{}
 	case 1:
-		values = funcValue.Call([]reflect.Value{ctxValue})
+		in = []reflect.Value{ctxValue}
 	default:
-		in := make([]reflect.Value, 0, numIn)
+		bindStart := time.Now()
+		in = make([]reflect.Value, 0, numIn)
 		in = append(in, ctxValue)
 		for i := 1; i < numIn; i++ {
+			// Inject provided services
+			if ctx.engine != nil && ctx.engine.services.provides(funcType.In(i)) {
+				service, err := ctx.engine.services.resolve(ctx, funcType.In(i))
+				if err != nil {
+					var httpErr *httperrors.Error
+					if errors.As(err, &httpErr) {
+						return httpErr
+					}
+					_ = ctx.Error(err)
+					return httperrors.ErrInternalServerError
+				}
+				in = append(in, service)
+				continue
+			}
+
 			// Bind handler params
 			parameter := reflect.New(funcType.In(i)).Interface()
-			if err := bind(ctx, parameter); err != nil {
+			var err error
+			if source := bindingSourceOf(funcType.In(i)); source != "" {
+				err = bindSource(ctx, parameter, source)
+			} else {
+				err = bind(ctx, parameter)
+			}
+			if err != nil {
 				var httpErr *httperrors.Error
 				if errors.As(err, &httpErr) {
 					return httpErr
@@ -47,9 +75,15 @@ func call(ctx *Context, handler HandlerFunc) any {
 			}
 			in = append(in, reflect.ValueOf(parameter).Elem())
 		}
-		values = funcValue.Call(in)
+		ctx.timing.since(ServerTimingBind, bindStart)
 	}
 
+	// Middleware calling Next do not count the rest of the chain, timed by
+	// its own handlers.
+	handlerStart, next := time.Now(), ctx.next
+	values := funcValue.Call(in)
+	ctx.timing.add(ServerTimingHandler, time.Since(handlerStart)-(ctx.next-next), "")
+
 	switch numOut {
 	case 0:
 		return nil
@@ -611,6 +611,33 @@ func createTestContext(engine *Engine, method, path, body string) *Context {
 	}
 }
 
+// serve sends a request to handler and returns the response. header holds
+// name and value pairs added to the request; bodies are sent as JSON unless
+// header sets the Content-Type.
+func serve(handler http.Handler, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Add(header[i], header[i+1])
+	}
+	if body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// findCookie returns the cookie name set by the response, or nil.
+func findCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
 // Test error marshaling
 
 func TestCall_HTTPErrorMarshaling(t *testing.T) {
@@ -0,0 +1,545 @@
+package fox
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+const (
+	defaultCompressionMinSize      = 1024
+	defaultMaxDecompressedBodySize = 10 << 20
+)
+
+var errUnsupportedContentEncoding = errors.New("fox: unsupported content encoding")
+
+// DefaultCompressibleContentTypes are the media types compressed by default.
+// Entries ending with "/" match a whole type, entries starting with "+" a
+// structured syntax suffix.
+var DefaultCompressibleContentTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/x-www-form-urlencoded",
+	"image/svg+xml",
+	"+json",
+	"+xml",
+}
+
+// Encoding is an HTTP content coding. gzip, deflate, zstd and brotli are
+// built in, other codings can be added by wrapping their implementation.
+type Encoding struct {
+	// Name is the content coding token, for example "gzip". Required.
+	Name string
+
+	// NewWriter returns a writer compressing to w. Closing it must flush the
+	// compressed stream but not close w. Required.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader returns a reader decompressing r. Without it request bodies
+	// in this coding are rejected.
+	// Optional.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipEncoding returns the gzip content coding at the given compression
+// level, for example gzip.DefaultCompression.
+func GzipEncoding(level int) Encoding {
+	pool := sync.Pool{New: func() any {
+		w, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			return err
+		}
+		return w
+	}}
+	return Encoding{
+		Name: "gzip",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			switch v := pool.Get().(type) {
+			case error:
+				return nil, v
+			case *gzip.Writer:
+				v.Reset(w)
+				return &pooledWriter{WriteCloser: v, flush: v.Flush, release: func() { pool.Put(v) }}, nil
+			}
+			return nil, errors.New("fox: invalid gzip writer")
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	}
+}
+
+// DeflateEncoding returns the deflate content coding (zlib-less DEFLATE
+// stream, as sent by most clients) at the given compression level.
+func DeflateEncoding(level int) Encoding {
+	return Encoding{
+		Name: "deflate",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			fw, err := flate.NewWriter(w, level)
+			if err != nil {
+				return nil, err
+			}
+			return &pooledWriter{WriteCloser: fw, flush: fw.Flush}, nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	}
+}
+
+// ZstdEncoding returns the zstd content coding at the given compression
+// level, for example zstd.SpeedDefault.
+func ZstdEncoding(level zstd.EncoderLevel) Encoding {
+	pool := sync.Pool{New: func() any {
+		w, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		return w
+	}}
+	return Encoding{
+		Name: "zstd",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			switch v := pool.Get().(type) {
+			case error:
+				return nil, v
+			case *zstd.Encoder:
+				v.Reset(w)
+				return &pooledWriter{WriteCloser: v, flush: v.Flush, release: func() { pool.Put(v) }}, nil
+			}
+			return nil, errors.New("fox: invalid zstd writer")
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			return d.IOReadCloser(), nil
+		},
+	}
+}
+
+// BrotliEncoding returns the br content coding at the given compression
+// level, for example brotli.DefaultCompression.
+func BrotliEncoding(level int) Encoding {
+	pool := sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, level)
+	}}
+	return Encoding{
+		Name: "br",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			bw := pool.Get().(*brotli.Writer)
+			bw.Reset(w)
+			return &pooledWriter{WriteCloser: bw, flush: bw.Flush, release: func() { pool.Put(bw) }}, nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(brotli.NewReader(r)), nil
+		},
+	}
+}
+
+// pooledWriter adapts compressors to a common Flush and returns them to
+// their pool once closed.
+type pooledWriter struct {
+	io.WriteCloser
+	flush   func() error
+	release func()
+}
+
+func (w *pooledWriter) Flush() error {
+	return w.flush()
+}
+
+func (w *pooledWriter) Close() error {
+	err := w.WriteCloser.Close()
+	if w.release != nil {
+		w.release()
+		w.release = nil
+	}
+	return err
+}
+
+// CompressionConfig defines the config for Compression middleware.
+type CompressionConfig struct {
+	// Encodings are the supported content codings in order of preference,
+	// default is gzip then deflate at the default level. Add ZstdEncoding
+	// and BrotliEncoding to negotiate zstd and br.
+	// Optional.
+	Encodings []Encoding
+
+	// MinSize is the smallest response body compressed, default is 1KB.
+	// Optional.
+	MinSize int
+
+	// ContentTypes are the compressed media types, default is
+	// DefaultCompressibleContentTypes.
+	// Optional.
+	ContentTypes []string
+
+	// MaxDecompressedSize bounds decompressed request bodies, default is
+	// 10MB. Larger bodies fail binding with 413
+	// httperrors.ErrRequestEntityTooLarge.
+	// Optional.
+	MaxDecompressedSize int64
+
+	// DisableRequestDecompression leaves Content-Encoding request bodies
+	// untouched.
+	// Optional.
+	DisableRequestDecompression bool
+}
+
+// Compression returns a middleware that compresses responses in the coding
+// negotiated from Accept-Encoding and decompresses request bodies sent with
+// Content-Encoding, so handlers bind them transparently.
+//
+// Responses are compressed when their body reaches MinSize and their
+// Content-Type matches ContentTypes. Responses that already have a
+// Content-Encoding, server-sent event streams, bodyless responses and
+// hijacked connections are sent as is. Request bodies in an unsupported
+// coding are rejected with 415 and an Accept-Encoding header, malformed ones
+// with 400.
+func Compression(config ...CompressionConfig) gin.HandlerFunc {
+	var conf CompressionConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if len(conf.Encodings) == 0 {
+		conf.Encodings = []Encoding{
+			GzipEncoding(gzip.DefaultCompression),
+			DeflateEncoding(flate.DefaultCompression),
+		}
+	}
+	if conf.MinSize <= 0 {
+		conf.MinSize = defaultCompressionMinSize
+	}
+	if len(conf.ContentTypes) == 0 {
+		conf.ContentTypes = DefaultCompressibleContentTypes
+	}
+	if conf.MaxDecompressedSize <= 0 {
+		conf.MaxDecompressedSize = defaultMaxDecompressedBodySize
+	}
+
+	var decodable []string
+	for _, enc := range conf.Encodings {
+		if enc.NewReader != nil {
+			decodable = append(decodable, enc.Name)
+		}
+	}
+	acceptEncoding := strings.Join(decodable, ", ")
+
+	return func(c *gin.Context) {
+		if !conf.DisableRequestDecompression && c.Request.Header.Get("Content-Encoding") != "" {
+			err := decompressRequest(c.Request, conf.Encodings, conf.MaxDecompressedSize)
+			if errors.Is(err, errUnsupportedContentEncoding) {
+				if acceptEncoding != "" {
+					c.Header("Accept-Encoding", acceptEncoding)
+				}
+				AbortWithError(c, httperrors.ErrUnsupportedMediaType)
+				return
+			}
+			if err != nil {
+				AbortWithError(c, httperrors.ErrInvalidArguments)
+				return
+			}
+		}
+
+		enc, ok := negotiateEncoding(c.GetHeader("Accept-Encoding"), conf.Encodings)
+		if !ok || c.Request.Method == http.MethodHead {
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       enc,
+			config:         &conf,
+			status:         http.StatusOK,
+		}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the coding with the highest quality value in
+// accept, preferring the order of encodings on ties.
+func negotiateEncoding(accept string, encodings []Encoding) (Encoding, bool) {
+	if accept == "" {
+		return Encoding{}, false
+	}
+
+	qualities := map[string]float64{}
+	for part := range strings.SplitSeq(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[name] = q
+	}
+
+	var (
+		best  Encoding
+		bestQ float64
+	)
+	for _, enc := range encodings {
+		q, ok := qualities[enc.Name]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// decompressRequest replaces the body of req with its decoded content,
+// bounded by limit.
+func decompressRequest(req *http.Request, encodings []Encoding, limit int64) error {
+	codings := strings.Split(req.Header.Get("Content-Encoding"), ",")
+	body := req.Body
+	closers := []io.Closer{req.Body}
+	var reader io.Reader = body
+
+	// Codings are listed in the order they were applied.
+	for _, coding := range slices.Backward(codings) {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "identity" {
+			continue
+		}
+		idx := slices.IndexFunc(encodings, func(enc Encoding) bool {
+			return enc.Name == coding && enc.NewReader != nil
+		})
+		if idx < 0 {
+			return errUnsupportedContentEncoding
+		}
+		decoded, err := encodings[idx].NewReader(reader)
+		if err != nil {
+			return err
+		}
+		reader = decoded
+		closers = append(closers, decoded)
+	}
+
+	req.Body = &decompressedBody{
+		Reader:  &limitedBody{ReadCloser: io.NopCloser(reader), limit: limit},
+		closers: closers,
+	}
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	return nil
+}
+
+type decompressedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	var errs []error
+	for _, closer := range slices.Backward(b.closers) {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// compressWriter buffers the start of a response until it can decide
+// whether to compress it.
+type compressWriter struct {
+	gin.ResponseWriter
+
+	encoding Encoding
+	config   *CompressionConfig
+
+	status  int
+	buf     []byte
+	size    int
+	decided bool
+	wrote   bool
+	cw      io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if code > 0 && !w.decided {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	w.size += len(b)
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) >= w.config.MinSize || w.isEventStream() {
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	if w.cw != nil {
+		return w.cw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Size() int {
+	if !w.wrote {
+		return w.ResponseWriter.Size()
+	}
+	return w.size
+}
+
+func (w *compressWriter) Written() bool {
+	return w.wrote || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= w.config.MinSize)
+	}
+	if f, ok := w.cw.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *compressWriter) isEventStream() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+}
+
+// decide sends the header, compressing the body when large enough is true
+// and the response qualifies, then writes the buffered body.
+func (w *compressWriter) decide(largeEnough bool) error {
+	w.decided = true
+
+	header := w.Header()
+	compressible := w.compressibleType()
+	if compressible {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if largeEnough && compressible && bodyAllowed(w.status) &&
+		header.Get("Content-Encoding") == "" && !w.isEventStream() {
+		cw, err := w.encoding.NewWriter(w.ResponseWriter)
+		if err == nil {
+			w.cw = cw
+			header.Set("Content-Encoding", w.encoding.Name)
+			header.Del("Content-Length")
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.cw != nil {
+		_, err = w.cw.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) compressibleType() bool {
+	contentType := filterFlags(w.Header().Get("Content-Type"))
+	if contentType == "" {
+		return false
+	}
+	for _, allowed := range w.config.ContentTypes {
+		switch {
+		case strings.HasPrefix(allowed, "+"):
+			if strings.HasSuffix(contentType, allowed) {
+				return true
+			}
+		case strings.HasSuffix(allowed, "/"):
+			if strings.HasPrefix(contentType, allowed) {
+				return true
+			}
+		case contentType == allowed:
+			return true
+		}
+	}
+	return false
+}
+
+// finish writes what is still buffered and closes the compressor.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if !w.wrote {
+			// Nothing was written; leave the header to gin.
+			w.ResponseWriter.WriteHeader(w.status)
+			return
+		}
+		_ = w.decide(len(w.buf) >= w.config.MinSize)
+	}
+	if w.cw != nil {
+		_ = w.cw.Close()
+		w.cw = nil
+	}
+}
+
+// bodyAllowed reports whether a response with status may have a body.
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
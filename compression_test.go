@@ -0,0 +1,265 @@
+package fox
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	encodings := []Encoding{GzipEncoding(gzip.DefaultCompression), DeflateEncoding(flate.DefaultCompression)}
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", "gzip"},
+		{"gzip;q=0, *", "deflate"},
+		{"br, identity", ""},
+	}
+	for _, tt := range tests {
+		enc, ok := negotiateEncoding(tt.accept, encodings)
+		assert.Equal(t, tt.want != "", ok, tt.accept)
+		assert.Equal(t, tt.want, enc.Name, tt.accept)
+	}
+}
+
+func TestCompression_Response(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("fox ", 1000)
+	router := New()
+	router.Use(Compression())
+	router.GET("/large", func() string { return large })
+	router.GET("/small", func() string { return "small" })
+	router.GET("/png", func(c *Context) {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+	})
+	router.GET("/encoded", func(c *Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", gzipBytes(t, []byte(large)))
+	})
+	router.DELETE("/item", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	get := func(method, path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Accept-Encoding", accept)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(http.MethodGet, "/large", "gzip")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Less(t, w.Body.Len(), len(large))
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	w = get(http.MethodGet, "/large", "deflate")
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	body, err = io.ReadAll(flate.NewReader(w.Body))
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	w = get(http.MethodGet, "/large", "")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, w.Body.String())
+
+	w = get(http.MethodGet, "/small", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, "small", w.Body.String())
+
+	w = get(http.MethodGet, "/png", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, w.Body.String())
+
+	w = get(http.MethodGet, "/encoded", "gzip")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, gzipBytes(t, []byte(large)), w.Body.Bytes())
+
+	w = get(http.MethodDelete, "/item", "gzip")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestCompression_SkipsEventStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(Compression(CompressionConfig{MinSize: 1}))
+	router.GET("/events", gin.HandlerFunc(func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.SSEvent("message", "hello")
+		c.Writer.Flush()
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	router.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), "data:hello")
+	assert.True(t, w.Flushed)
+}
+
+func TestCompression_FlushStreams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(Compression(CompressionConfig{MinSize: 1}))
+	router.GET("/stream", gin.HandlerFunc(func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain")
+		_, _ = c.Writer.WriteString("part 1\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("part 2\n")
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "part 1\npart 2\n", string(body))
+}
+
+type compressionPayload struct {
+	Name string `json:"name"`
+}
+
+func TestCompression_ZstdAndBrotli(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("fox ", 1000)
+	router := New()
+	router.Use(Compression(CompressionConfig{Encodings: []Encoding{
+		ZstdEncoding(zstd.SpeedDefault),
+		BrotliEncoding(brotli.DefaultCompression),
+		GzipEncoding(gzip.DefaultCompression),
+	}}))
+	router.GET("/large", func() string { return large })
+	router.POST("/echo-ctx", func(c *Context, p *compressionPayload) string {
+		return p.Name
+	})
+
+	for _, tt := range []struct {
+		accept    string
+		encoding  string
+		newReader func(io.Reader) (io.Reader, error)
+	}{
+		{"gzip, deflate, br, zstd", "zstd", func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+		{"gzip, br", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+	} {
+		// Requests are sent twice to reuse pooled writers.
+		for range 2 {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/large", nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.encoding, w.Header().Get("Content-Encoding"))
+			assert.Less(t, w.Body.Len(), len(large))
+			r, err := tt.newReader(w.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, large, string(body))
+		}
+	}
+
+	for encoding, compress := range map[string]func(io.Writer) io.WriteCloser{
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, err := zstd.NewWriter(w)
+			require.NoError(t, err)
+			return zw
+		},
+		"br": func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	} {
+		var buf bytes.Buffer
+		cw := compress(&buf)
+		_, err := cw.Write([]byte(`{"name":"fox"}`))
+		require.NoError(t, err)
+		require.NoError(t, cw.Close())
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/echo-ctx", &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, encoding)
+		assert.Equal(t, "fox", w.Body.String(), encoding)
+	}
+}
+
+func TestCompression_RequestDecompression(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(Compression(CompressionConfig{MaxDecompressedSize: 64}))
+	router.POST("/echo-ctx", func(c *Context, p *compressionPayload) string {
+		return p.Name
+	})
+
+	post := func(path string, body []byte, encoding string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/echo-ctx", gzipBytes(t, []byte(`{"name":"fox"}`)), "gzip")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fox", w.Body.String())
+
+	// A body that decompresses beyond the limit is rejected.
+	bomb := gzipBytes(t, []byte(`{"name":"`+strings.Repeat("a", 1000)+`"}`))
+	w = post("/echo-ctx", bomb, "gzip")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = post("/echo-ctx", []byte(`{"name":"fox"}`), "br")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "gzip, deflate", w.Header().Get("Accept-Encoding"))
+
+	w = post("/echo-ctx", []byte(`not gzip`), "gzip")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,419 @@
+package fox
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/utils"
+)
+
+// servicesContextKey stores the *serviceScope of the current request.
+const servicesContextKey = "_fox-gonic/fox/services"
+
+// Scope is the lifetime of a service provided with Engine.Provide.
+type Scope int
+
+const (
+	// Singleton services are constructed once, on first use, and shared by
+	// all requests.
+	Singleton Scope = iota
+
+	// RequestScoped services are constructed once per request and cleaned up
+	// when the request ends.
+	RequestScoped
+
+	// Transient services are constructed for every parameter needing them,
+	// and cleaned up when the request ends.
+	Transient
+)
+
+// String returns the scope name.
+func (s Scope) String() string {
+	switch s {
+	case Singleton:
+		return "singleton"
+	case RequestScoped:
+		return "request"
+	case Transient:
+		return "transient"
+	}
+	return fmt.Sprintf("Scope(%d)", int(s))
+}
+
+var (
+	contextType = reflect.TypeFor[*Context]()
+	errorType   = reflect.TypeFor[error]()
+	cleanupType = reflect.TypeFor[func()]()
+)
+
+// container resolves the services of an Engine by type.
+type container struct {
+	mu        sync.RWMutex
+	providers map[reflect.Type]*provider
+}
+
+// provider constructs the service of one type.
+type provider struct {
+	scope       Scope
+	constructor reflect.Value
+	deps        []reflect.Type
+	hasCleanup  bool
+	hasError    bool
+	// needsRequest is set when the service depends on the request, directly
+	// or through its dependencies.
+	needsRequest bool
+
+	mu    sync.Mutex
+	value reflect.Value
+}
+
+// serviceScope holds the request-scoped services of a request.
+type serviceScope struct {
+	mu       sync.Mutex
+	values   map[reflect.Type]reflect.Value
+	cleanups []func()
+}
+
+// Provide registers the constructor of a service, which handlers receive by
+// declaring a parameter of the constructor result type:
+//
+//	router.Provide(fox.Singleton, NewDB)                 // func(cfg *Config) (*sql.DB, error)
+//	router.Provide(fox.RequestScoped, NewUserRepo)       // func(db *sql.DB) *UserRepo
+//	router.Provide(fox.Transient, func(c *fox.Context) *Audit { ... })
+//	router.POST("/users", func(c *fox.Context, repo *UserRepo, args CreateUser) (*User, error) {
+//		...
+//	})
+//
+// A constructor is a func(deps...) T, func(deps...) (T, error) or, for
+// request-scoped and transient services, func(deps...) (T, func(), error)
+// whose cleanup function runs when the request ends. Its parameters are
+// other services, which must be provided first, or *Context for services
+// that are not singletons. Providing a type again replaces its constructor,
+// so tests can swap in fakes.
+//
+// Handler parameters after *Context whose type is provided are injected, the
+// others are bound from the request. Route registration panics when a
+// parameter is neither provided nor bindable, such as an interface. Services
+// are provided before the routes using them: providing a type that registered
+// handlers bind from the request panics.
+func (engine *Engine) Provide(scope Scope, constructor any) {
+	if typ := reflect.TypeOf(constructor); typ != nil && typ.Kind() == reflect.Func && typ.NumOut() > 0 {
+		if handler, ok := engine.boundParams[typ.Out(0)]; ok {
+			panic(fmt.Sprintf("fox: %v is provided after %s binds it from the request, provide services before registering routes", typ.Out(0), handler))
+		}
+	}
+	if engine.services == nil {
+		engine.services = &container{}
+	}
+	engine.services.provide(scope, reflect.ValueOf(constructor))
+}
+
+// recordBoundParams records the parameter types of handler bound from the
+// request.
+func (engine *Engine) recordBoundParams(handler HandlerFunc) {
+	typ := reflect.TypeOf(handler)
+	if typ.Kind() != reflect.Func {
+		return
+	}
+	for i := 1; i < typ.NumIn(); i++ {
+		if param := typ.In(i); !engine.services.provides(param) {
+			if engine.boundParams == nil {
+				engine.boundParams = make(map[reflect.Type]string)
+			}
+			engine.boundParams[param] = utils.NameOfFunction(handler)
+		}
+	}
+}
+
+// ProvideValue registers value as a singleton service of its type.
+func (engine *Engine) ProvideValue(value any) {
+	if value == nil {
+		panic("fox: ProvideValue requires a non-nil value")
+	}
+	v := reflect.ValueOf(value)
+	engine.Provide(Singleton, reflect.MakeFunc(
+		reflect.FuncOf(nil, []reflect.Type{v.Type()}, false),
+		func([]reflect.Value) []reflect.Value { return []reflect.Value{v} },
+	).Interface())
+}
+
+// Resolve returns the service of type T, for middleware and code outside of
+// handler parameters.
+func Resolve[T any](c *Context) (T, error) {
+	var zero T
+	if c == nil || c.engine == nil {
+		return zero, errors.New("fox: Resolve requires a handler Context")
+	}
+	v, err := c.engine.services.resolve(c, reflect.TypeFor[T]())
+	if err != nil {
+		return zero, err
+	}
+	return v.Interface().(T), nil
+}
+
+func (s *container) provide(scope Scope, constructor reflect.Value) {
+	if constructor.Kind() != reflect.Func || constructor.IsNil() {
+		panic(fmt.Sprintf("fox: service constructor must be a function, got %v", constructor.Type()))
+	}
+	typ := constructor.Type()
+	name := typ.String()
+	if scope < Singleton || scope > Transient {
+		panic(fmt.Sprintf("fox: invalid scope %v for service constructor %s", scope, name))
+	}
+
+	p := &provider{scope: scope, constructor: constructor, needsRequest: scope == RequestScoped}
+	switch out := typ.NumOut(); {
+	case out == 1:
+	case out == 2 && typ.Out(1) == errorType:
+		p.hasError = true
+	case out == 3 && typ.Out(1) == cleanupType && typ.Out(2) == errorType:
+		p.hasCleanup, p.hasError = true, true
+	default:
+		panic(fmt.Sprintf("fox: service constructor %s must return T, (T, error) or (T, func(), error)", name))
+	}
+	if p.hasCleanup {
+		if scope == Singleton {
+			panic(fmt.Sprintf("fox: singleton constructor %s cannot return a cleanup function", name))
+		}
+		p.needsRequest = true
+	}
+
+	service := typ.Out(0)
+	if service == contextType {
+		panic("fox: *Context cannot be provided as a service")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.providers == nil {
+		s.providers = make(map[reflect.Type]*provider)
+	}
+	for i := range typ.NumIn() {
+		dep := typ.In(i)
+		switch dp, ok := s.providers[dep]; {
+		case dep == contextType:
+			p.needsRequest = true
+		case dep == service:
+			panic(fmt.Sprintf("fox: service constructor %s depends on itself", name))
+		case !ok:
+			panic(fmt.Sprintf("fox: service constructor %s depends on %v, which is not provided", name, dep))
+		case dp.needsRequest:
+			p.needsRequest = true
+		}
+		p.deps = append(p.deps, dep)
+	}
+	if scope == Singleton && p.needsRequest {
+		panic(fmt.Sprintf("fox: singleton constructor %s depends on request services", name))
+	}
+
+	// Providing a type again may close a cycle, or make singletons depend on
+	// request services, so the whole graph is checked.
+	previous, replaced := s.providers[service]
+	s.providers[service] = p
+	needsRequest, err := s.check(service)
+	if err != nil {
+		if replaced {
+			s.providers[service] = previous
+		} else {
+			delete(s.providers, service)
+		}
+		panic(fmt.Sprintf("fox: service constructor %s: %v", name, err))
+	}
+	for p, needs := range needsRequest {
+		p.needsRequest = needs
+	}
+}
+
+// check returns whether each provider depends on the request, directly or
+// through its dependencies, or an error for dependency cycles and singletons
+// depending on request services. The graph is walked from service first, so
+// that errors name the path from it.
+func (s *container) check(service reflect.Type) (map[*provider]bool, error) {
+	var (
+		needsRequest = make(map[*provider]bool, len(s.providers))
+		visiting     = make(map[*provider]bool)
+		visit        func(typ reflect.Type, path []reflect.Type) error
+	)
+	visit = func(typ reflect.Type, path []reflect.Type) error {
+		p := s.providers[typ]
+		if _, done := needsRequest[p]; done {
+			return nil
+		}
+		path = append(path, typ)
+		if visiting[p] {
+			cycle := make([]string, 0, len(path))
+			for _, t := range path[slices.Index(path, typ):] {
+				cycle = append(cycle, t.String())
+			}
+			return fmt.Errorf("dependency cycle %s", strings.Join(cycle, " -> "))
+		}
+		visiting[p] = true
+
+		needs := p.scope == RequestScoped || p.hasCleanup
+		for _, dep := range p.deps {
+			if dep == contextType {
+				needs = true
+				continue
+			}
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+			needs = needs || needsRequest[s.providers[dep]]
+		}
+		if p.scope == Singleton && needs {
+			return fmt.Errorf("singleton %v depends on request services", typ)
+		}
+		needsRequest[p] = needs
+		return nil
+	}
+	if err := visit(service, nil); err != nil {
+		return nil, err
+	}
+	for typ := range s.providers {
+		if err := visit(typ, nil); err != nil {
+			return nil, err
+		}
+	}
+	return needsRequest, nil
+}
+
+// provides reports whether typ is provided.
+func (s *container) provides(typ reflect.Type) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.providers[typ]
+	return ok
+}
+
+// resolve returns the service of typ. c is nil outside of requests.
+func (s *container) resolve(c *Context, typ reflect.Type) (reflect.Value, error) {
+	if typ == contextType && c != nil {
+		return reflect.ValueOf(c), nil
+	}
+	var p *provider
+	if s != nil {
+		s.mu.RLock()
+		p = s.providers[typ]
+		s.mu.RUnlock()
+	}
+	if p == nil {
+		return reflect.Value{}, fmt.Errorf("fox: service %v is not provided", typ)
+	}
+
+	switch p.scope {
+	case Singleton:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.value.IsValid() {
+			v, err := s.construct(nil, p)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			p.value = v
+		}
+		return p.value, nil
+	case RequestScoped:
+		scope := servicesFromContext(c)
+		if scope == nil {
+			return reflect.Value{}, fmt.Errorf("fox: request service %v resolved outside of a request", typ)
+		}
+		scope.mu.Lock()
+		v, ok := scope.values[typ]
+		scope.mu.Unlock()
+		if ok {
+			return v, nil
+		}
+		v, err := s.construct(c, p)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		scope.mu.Lock()
+		defer scope.mu.Unlock()
+		if scope.values == nil {
+			scope.values = make(map[reflect.Type]reflect.Value)
+		}
+		scope.values[typ] = v
+		return v, nil
+	default:
+		return s.construct(c, p)
+	}
+}
+
+// construct calls the constructor of p with its dependencies.
+func (s *container) construct(c *Context, p *provider) (reflect.Value, error) {
+	in := make([]reflect.Value, len(p.deps))
+	for i, dep := range p.deps {
+		v, err := s.resolve(c, dep)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		in[i] = v
+	}
+
+	out := p.constructor.Call(in)
+	if p.hasError {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if p.hasCleanup {
+		if cleanup, _ := out[1].Interface().(func()); cleanup != nil {
+			scope := servicesFromContext(c)
+			if scope == nil {
+				cleanup()
+				return reflect.Value{}, fmt.Errorf("fox: request service %v resolved outside of a request", p.constructor.Type().Out(0))
+			}
+			scope.mu.Lock()
+			scope.cleanups = append(scope.cleanups, cleanup)
+			scope.mu.Unlock()
+		}
+	}
+	return out[0], nil
+}
+
+// startServiceScope starts the service scope of the request when the engine
+// provides services and the request has none yet. It returns nil otherwise.
+func startServiceScope(c *gin.Context, engine *Engine) *serviceScope {
+	if engine == nil || engine.services == nil {
+		return nil
+	}
+	if _, exists := c.Get(servicesContextKey); exists {
+		return nil
+	}
+	scope := &serviceScope{}
+	c.Set(servicesContextKey, scope)
+	return scope
+}
+
+func servicesFromContext(c *Context) *serviceScope {
+	if c == nil || c.Context == nil {
+		return nil
+	}
+	if v, exists := c.Get(servicesContextKey); exists {
+		scope, _ := v.(*serviceScope)
+		return scope
+	}
+	return nil
+}
+
+// close runs the cleanup functions in reverse construction order.
+func (scope *serviceScope) close() {
+	scope.mu.Lock()
+	cleanups := scope.cleanups
+	scope.cleanups = nil
+	scope.mu.Unlock()
+
+	for _, cleanup := range slices.Backward(cleanups) {
+		cleanup()
+	}
+}
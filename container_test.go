@@ -0,0 +1,231 @@
+package fox
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+type testDB struct{ name string }
+
+type testUserRepo struct {
+	db        *testDB
+	requestID string
+}
+
+type testUserStore interface {
+	Find(id string) string
+}
+
+type testMemoryUserStore struct{}
+
+func (testMemoryUserStore) Find(id string) string { return "user " + id }
+
+type testCreateUser struct {
+	Name string `json:"name"`
+}
+
+func TestEngine_Provide(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		dbCount, repoCount, cleanups int
+		events                       []string
+	)
+	router := New()
+	router.Provide(Singleton, func() *testDB {
+		dbCount++
+		return &testDB{name: "main"}
+	})
+	router.Provide(RequestScoped, func(c *Context, db *testDB) (*testUserRepo, func(), error) {
+		repoCount++
+		return &testUserRepo{db: db, requestID: c.GetHeader("X-Request-ID")}, func() {
+			cleanups++
+			events = append(events, "cleanup")
+		}, nil
+	})
+	router.Provide(Singleton, func() testUserStore { return testMemoryUserStore{} })
+
+	router.Use(func(c *Context, repo *testUserRepo) {
+		events = append(events, "middleware")
+		c.Set("repo", repo)
+	})
+	router.POST("/users", func(c *Context, repo *testUserRepo, store testUserStore, args testCreateUser) (string, error) {
+		events = append(events, "handler")
+		assert.Same(t, c.MustGet("repo"), repo)
+		return repo.db.name + " " + args.Name + " " + store.Find("1"), nil
+	})
+
+	for i := range 2 {
+		events = nil
+		w := serve(router, http.MethodPost, "/users", `{"name":"gopher"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "main gopher user 1", w.Body.String())
+		assert.Equal(t, []string{"middleware", "handler", "cleanup"}, events)
+		assert.Equal(t, 1, dbCount)
+		assert.Equal(t, i+1, repoCount)
+		assert.Equal(t, i+1, cleanups)
+	}
+}
+
+func TestEngine_ProvideTransient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type counter struct{ n int }
+	count := 0
+	router := New()
+	router.Provide(Transient, func() *counter {
+		count++
+		return &counter{n: count}
+	})
+	router.GET("/", func(c *Context, a *counter) int {
+		b, err := Resolve[*counter](c)
+		require.NoError(t, err)
+		return a.n*10 + b.n
+	})
+
+	w := serve(router, http.MethodGet, "/", "")
+	assert.Equal(t, "12", w.Body.String())
+}
+
+func TestEngine_ProvideValueAndOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.ProvideValue(&testDB{name: "production"})
+	router.GET("/", func(c *Context, db *testDB) string { return db.name })
+
+	assert.Equal(t, "production", serve(router, http.MethodGet, "/", "").Body.String())
+
+	// Tests swap in fakes after the routes are registered.
+	router.ProvideValue(&testDB{name: "fake"})
+	assert.Equal(t, "fake", serve(router, http.MethodGet, "/", "").Body.String())
+}
+
+func TestEngine_ProvideErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Provide(RequestScoped, func() (*testDB, error) { return nil, errors.New("connection refused") })
+	router.Provide(RequestScoped, func() (*testUserRepo, error) { return nil, httperrors.ErrServiceUnavailable })
+	router.GET("/db", func(c *Context, db *testDB) string { return "unreachable" })
+	router.GET("/repo", func(c *Context, repo *testUserRepo) string { return "unreachable" })
+
+	w := serve(router, http.MethodGet, "/db", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+
+	w = serve(router, http.MethodGet, "/repo", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestEngine_ProvideValidation(t *testing.T) {
+	router := New()
+
+	assert.PanicsWithValue(t, "fox: service constructor func(*fox.testDB) *fox.testUserRepo depends on *fox.testDB, which is not provided", func() {
+		router.Provide(RequestScoped, func(db *testDB) *testUserRepo { return nil })
+	})
+	assert.Panics(t, func() { router.Provide(Singleton, "not a function") })
+	assert.Panics(t, func() { router.Provide(Singleton, func() {}) })
+	assert.Panics(t, func() { router.Provide(Singleton, func() (*testDB, string) { return nil, "" }) })
+	assert.Panics(t, func() { router.Provide(Singleton, func() (*testDB, func(), error) { return nil, nil, nil }) })
+	assert.Panics(t, func() { router.Provide(Singleton, func(c *Context) *testDB { return nil }) })
+	assert.Panics(t, func() { router.Provide(Scope(9), func() *testDB { return nil }) })
+
+	router.Provide(RequestScoped, func() *testDB { return nil })
+	assert.PanicsWithValue(t, "fox: singleton constructor func(*fox.testDB) *fox.testUserRepo depends on request services", func() {
+		router.Provide(Singleton, func(db *testDB) *testUserRepo { return nil })
+	})
+
+	// Handler parameters must be provided or bindable.
+	assert.Panics(t, func() {
+		router.GET("/store", func(c *Context, store testUserStore) string { return "" })
+	})
+	assert.Panics(t, func() {
+		router.GET("/two", func(c *Context, a testCreateUser, b testCreateUser) string { return "" })
+	})
+	assert.NotPanics(t, func() {
+		router.GET("/db", func(c *Context, db *testDB, args testCreateUser) string { return "" })
+	})
+}
+
+func TestEngine_ProvideCycles(t *testing.T) {
+	type serviceA struct{}
+	type serviceB struct{}
+
+	router := New()
+	router.Provide(RequestScoped, func() *serviceB { return &serviceB{} })
+	router.Provide(RequestScoped, func(*serviceB) *serviceA { return &serviceA{} })
+	assert.PanicsWithValue(t, "fox: service constructor func(*fox.serviceA) *fox.serviceB: dependency cycle *fox.serviceB -> *fox.serviceA -> *fox.serviceB", func() {
+		router.Provide(RequestScoped, func(*serviceA) *serviceB { return &serviceB{} })
+	})
+
+	// The rejected constructor is not kept.
+	router.GET("/", func(c *Context, a *serviceA) string { return "ok" })
+	assert.Equal(t, "ok", serve(router, http.MethodGet, "/", "").Body.String())
+
+	// Replacing a dependency of a singleton with a request service.
+	router = New()
+	router.Provide(Singleton, func() *serviceB { return &serviceB{} })
+	router.Provide(Singleton, func(*serviceB) *serviceA { return &serviceA{} })
+	assert.PanicsWithValue(t, "fox: service constructor func() *fox.serviceB: singleton *fox.serviceA depends on request services", func() {
+		router.Provide(RequestScoped, func() *serviceB { return &serviceB{} })
+	})
+}
+
+func TestEngine_ProvideAfterRoutes(t *testing.T) {
+	router := New()
+
+	router.POST("/users", func(c *Context, args testCreateUser) string { return "" })
+	assert.PanicsWithValue(t, "fox: fox.testCreateUser is provided after github.com/fox-gonic/fox.TestEngine_ProvideAfterRoutes.func1 binds it from the request, provide services before registering routes", func() {
+		router.ProvideValue(testCreateUser{})
+	})
+
+	// Services that are not provided yet are bound from the request, so
+	// providing them afterwards panics.
+	router.GET("/repo", func(c *Context, repo *testUserRepo) string { return "" })
+	assert.Panics(t, func() { router.ProvideValue(&testUserRepo{}) })
+
+	// Structs without exported fields bind nothing but are accepted.
+	assert.NotPanics(t, func() {
+		router.GET("/empty", func(c *Context, _ struct{}) string { return "" })
+	})
+}
+
+func TestEngine_ProvideRouteInfo(t *testing.T) {
+	router := New()
+	router.ProvideValue(&testDB{})
+	router.POST("/users", func(c *Context, db *testDB, args testCreateUser) string { return "" })
+
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, []reflect.Type{reflect.TypeFor[*testDB]()}, routes[0].Services)
+
+	manifest := RouteManifestFromEngine(router)
+	require.Len(t, manifest.Routes, 1)
+	require.Len(t, manifest.Routes[0].InputTypes, 1)
+	assert.Equal(t, "testCreateUser", manifest.Routes[0].InputTypes[0].Name)
+}
+
+func TestResolve_WithoutEngine(t *testing.T) {
+	_, err := Resolve[*testDB](nil)
+	assert.Error(t, err)
+
+	_, err = Resolve[*testDB](&Context{})
+	assert.Error(t, err)
+}
+
+func TestScope_String(t *testing.T) {
+	assert.Equal(t, "singleton", Singleton.String())
+	assert.Equal(t, "request", RequestScoped.String())
+	assert.Equal(t, "transient", Transient.String())
+	assert.Equal(t, "Scope(9)", Scope(9).String())
+}
@@ -2,15 +2,25 @@ package fox
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
+	"reflect"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/fox-gonic/fox/logger"
+	"github.com/fox-gonic/fox/tracing"
 )
 
+// stdContextType is the context.Context handler parameter type.
+var stdContextType = reflect.TypeFor[context.Context]()
+
+// foxContextKey is the request context key of the *Context of handlers taking
+// a context.Context.
+type foxContextKey struct{}
+
 // Context with engine
 type Context struct {
 	*gin.Context
@@ -19,9 +29,15 @@ type Context struct {
 	Logger logger.Logger
 	// Request is the http request copy from gin.Context.
 	Request *http.Request
+
+	timing *serverTiming
+	// next is the time spent in the rest of the chain by Next, which is not
+	// handler time.
+	next time.Duration
 }
 
-// RequestBody return request body bytes
+// RequestBody return request body bytes, bounded by Engine.MaxBodySize and
+// BodyLimit; larger bodies fail with httperrors.ErrRequestEntityTooLarge.
 // see c.ShouldBindBodyWith
 func (c *Context) RequestBody() (body []byte, err error) {
 	if cb, ok := c.Get(gin.BodyBytesKey); ok {
@@ -31,6 +47,12 @@ func (c *Context) RequestBody() (body []byte, err error) {
 	}
 
 	if body == nil && c.Request != nil && c.Request.Body != nil {
+		if c.Context != nil && c.Context.Request == c.Request {
+			if err = checkBodyLimit(c.Context, c.engine); err != nil {
+				return body, err
+			}
+		}
+
 		var (
 			buf   bytes.Buffer
 			bodyR = io.TeeReader(c.Request.Body, &buf)
@@ -48,8 +70,10 @@ func (c *Context) RequestBody() (body []byte, err error) {
 }
 
 // TraceID returns the request trace ID. It checks the gin context, request
-// header, and response header in order, falling back to generating a new ID
-// which is then written to both the response header and gin context.
+// header, and response header in order, falling back to the W3C trace ID of
+// the current span, then to generating a new ID which is then written to both
+// the response header and gin context. Requests traced by the Tracing
+// middleware without request ID header use their W3C trace ID as request ID.
 //
 // Note: This method has a side effect when no trace ID exists. If you only
 // want to read without generating, check c.GetHeader(logger.TraceID) directly.
@@ -67,6 +91,11 @@ func (c *Context) TraceID() string {
 	}
 
 	id := logger.DefaultGenRequestID()
+	if c.Request != nil {
+		if span := tracing.SpanFromContext(c.Request.Context()); span != nil {
+			id = span.SpanContext().TraceID.String()
+		}
+	}
 
 	c.Header(logger.TraceID, id)
 	c.Set(logger.TraceID, id)
@@ -92,7 +121,13 @@ func (c *Context) Deadline() (deadline time.Time, ok bool) {
 
 func (c *Context) Next() {
 	c.Context.Request = c.Request
+	if c.timing == nil {
+		c.Context.Next()
+		return
+	}
+	start := time.Now()
 	c.Context.Next()
+	c.next += time.Since(start)
 }
 
 func (c *Context) Copy() *Context {
@@ -103,5 +138,40 @@ func (c *Context) Copy() *Context {
 		engine:  c.engine,
 		Logger:  c.Logger,
 		Request: c.Request,
+		timing:  c.timing,
+	}
+}
+
+// FromContext returns the *Context carried by the context.Context passed to
+// handlers declaring one in place of *Context, so transport-agnostic
+// functions can be registered directly:
+//
+//	func GetUser(ctx context.Context, req GetUserRequest) (*User, error) {
+//		if c, ok := fox.FromContext(ctx); ok {
+//			c.Header("Cache-Control", "no-store")
+//		}
+//		...
+//	}
+//
+//	router.GET("/users/:id", GetUser)
+//
+// A *Context is returned as is.
+func FromContext(ctx context.Context) (*Context, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if c, ok := ctx.(*Context); ok {
+		return c, c != nil
+	}
+	c, ok := ctx.Value(foxContextKey{}).(*Context)
+	return c, ok
+}
+
+// requestContext returns the request context carrying c.
+func (c *Context) requestContext() context.Context {
+	parent := context.Background()
+	if c.Request != nil {
+		parent = c.Request.Context()
 	}
+	return context.WithValue(parent, foxContextKey{}, c)
 }
@@ -567,6 +567,100 @@ func TestContext_Copy_Independence(t *testing.T) {
 	assert.Equal(t, "value", val)
 }
 
+// Test handlers taking context.Context
+
+type testGetUserRequest struct {
+	ID   string `uri:"id"`
+	Name string `query:"name"`
+}
+
+type testUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type testRequestContextKey struct{}
+
+// testGetUser is a transport-agnostic service function.
+func testGetUser(ctx context.Context, req testGetUserRequest) (*testUser, error) {
+	if c, ok := FromContext(ctx); ok {
+		c.Header("X-Handler", "fox")
+	}
+	name, _ := ctx.Value(testRequestContextKey{}).(string)
+	return &testUser{ID: req.ID, Name: name + req.Name}, nil
+}
+
+func TestHandler_StdContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(func(c *Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), testRequestContextKey{}, "mr. "))
+	})
+	router.GET("/users/:id", testGetUser)
+	router.GET("/ctx", func(ctx context.Context) string {
+		c, ok := FromContext(ctx)
+		require.True(t, ok)
+		return c.FullPath()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7?name=gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fox", w.Header().Get("X-Handler"))
+	assert.JSONEq(t, `{"id":"7","name":"mr. gopher"}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/ctx", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "/ctx", w.Body.String())
+
+	// Outside of fox, the function is called directly.
+	user, err := testGetUser(context.Background(), testGetUserRequest{ID: "1", Name: "cli"})
+	require.NoError(t, err)
+	assert.Equal(t, "cli", user.Name)
+}
+
+func TestHandler_StdContextManifest(t *testing.T) {
+	router := New()
+	router.GET("/std", func(ctx context.Context, req testGetUserRequest) (*testUser, error) { return nil, nil })
+	router.GET("/fox", func(ctx *Context, req testGetUserRequest) (*testUser, error) { return nil, nil })
+
+	manifest := RouteManifestFromEngine(router)
+	require.Len(t, manifest.Routes, 2)
+	assert.Equal(t, manifest.Routes[0].InputTypes, manifest.Routes[1].InputTypes)
+	assert.Equal(t, manifest.Routes[0].ResultTypes, manifest.Routes[1].ResultTypes)
+	require.Len(t, manifest.Routes[0].InputTypes, 1)
+	assert.Equal(t, "testGetUserRequest", manifest.Routes[0].InputTypes[0].Name)
+}
+
+func TestIsValidHandlerFunc_StdContext(t *testing.T) {
+	assert.True(t, IsValidHandlerFunc(func(ctx context.Context) {}))
+	assert.True(t, IsValidHandlerFunc(testGetUser))
+	assert.False(t, IsValidHandlerFunc(func(ctx context.Context, id int) {}))
+	assert.False(t, IsValidHandlerFunc(func(ctx *context.CancelFunc) {}))
+}
+
+func TestFromContext(t *testing.T) {
+	c, ok := FromContext(context.Background())
+	assert.Nil(t, c)
+	assert.False(t, ok)
+
+	c, ok = FromContext(nil) //nolint:staticcheck // nil context is handled
+	assert.Nil(t, c)
+	assert.False(t, ok)
+
+	foxCtx := &Context{Request: httptest.NewRequest(http.MethodGet, "/", nil)}
+	c, ok = FromContext(foxCtx)
+	assert.Same(t, foxCtx, c)
+	assert.True(t, ok)
+
+	c, ok = FromContext(foxCtx.requestContext())
+	assert.Same(t, foxCtx, c)
+	assert.True(t, ok)
+}
+
 // Benchmark tests
 
 func BenchmarkContext_RequestBody_FirstRead(b *testing.B) {
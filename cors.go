@@ -0,0 +1,202 @@
+package fox
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS attaches a CORS policy to the routes of the group registered after
+// the call, replacing the policy of outer groups. It returns the error of
+// config.Validate instead of attaching an invalid policy.
+//
+// Preflight requests are answered automatically: Access-Control-Allow-Methods
+// lists the methods registered with a CORS policy for the requested path,
+// limited to config.AllowMethods when set. The policy of a route is decided
+// when it is registered, so it does not depend on the route registry. It is
+// recorded as the "cors" route metadata.
+//
+//	api := router.Group("/api")
+//	if err := api.CORS(cors.Config{AllowOrigins: []string{"https://app.example.com"}}); err != nil {
+//		return err
+//	}
+func (group *RouterGroup) CORS(config cors.Config) error {
+	policy, err := newCORSPolicy(group.engine, config)
+	if err != nil {
+		return err
+	}
+	group.Use(policy.middleware())
+	return nil
+}
+
+// corsPolicy is a CORS config attached to routes through their metadata.
+type corsPolicy struct {
+	engine *Engine
+	config cors.Config
+	normal gin.HandlerFunc
+
+	// preflight caches the preflight handlers by allowed methods.
+	preflight sync.Map
+}
+
+func newCORSPolicy(engine *Engine, config cors.Config) (*corsPolicy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &corsPolicy{engine: engine, config: config, normal: cors.New(config)}, nil
+}
+
+func (p *corsPolicy) middleware() *Middleware {
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			// Policies of outer groups leave the routes of inner ones alone.
+			if policy := p.engine.corsRoutePolicy(c.Request.Method, c.FullPath()); policy != nil && policy != p {
+				return
+			}
+			if !isPreflight(c.Request) {
+				p.normal(c)
+				return
+			}
+
+			// Unmatched preflight requests reach the engine policy, which
+			// defers to the policy of the requested route.
+			policy := p
+			routes := p.engine.matchCORSRoutes(c.Request.URL.Path)
+			if c.FullPath() == "" {
+				if routePolicy := corsPolicyFor(routes, c.GetHeader("Access-Control-Request-Method")); routePolicy != nil {
+					policy = routePolicy
+				}
+			}
+			policy.handlePreflight(c, routes)
+		},
+		Meta: RouteMeta{"cors": p},
+	}
+}
+
+// handlePreflight answers a preflight request for the routes of its path.
+func (p *corsPolicy) handlePreflight(c *gin.Context, routes []corsRoute) {
+	var methods []string
+	for _, route := range routes {
+		if !slices.Contains(methods, route.Method) && (len(p.config.AllowMethods) == 0 ||
+			slices.ContainsFunc(p.config.AllowMethods, func(m string) bool { return strings.EqualFold(m, route.Method) })) {
+			methods = append(methods, route.Method)
+		}
+	}
+
+	if len(methods) == 0 {
+		// Without routes, such as for unmatched paths of the engine policy,
+		// the configured methods are allowed.
+		p.normal(c)
+		return
+	}
+
+	key := strings.Join(methods, ",")
+	handler, ok := p.preflight.Load(key)
+	if !ok {
+		config := p.config
+		config.AllowMethods = methods
+		handler, _ = p.preflight.LoadOrStore(key, cors.New(config))
+	}
+	handler.(gin.HandlerFunc)(c)
+}
+
+// MarshalJSON describes the policy in the route manifest.
+func (p *corsPolicy) MarshalJSON() ([]byte, error) {
+	description := map[string]any{}
+	if p.config.AllowAllOrigins {
+		description["allowOrigins"] = []string{"*"}
+	} else if len(p.config.AllowOrigins) > 0 {
+		description["allowOrigins"] = p.config.AllowOrigins
+	}
+	if len(p.config.AllowMethods) > 0 {
+		description["allowMethods"] = p.config.AllowMethods
+	}
+	if len(p.config.AllowHeaders) > 0 {
+		description["allowHeaders"] = p.config.AllowHeaders
+	}
+	if len(p.config.ExposeHeaders) > 0 {
+		description["exposeHeaders"] = p.config.ExposeHeaders
+	}
+	if p.config.AllowCredentials {
+		description["allowCredentials"] = true
+	}
+	if p.config.MaxAge > 0 {
+		description["maxAge"] = p.config.MaxAge.String()
+	}
+	return json.Marshal(description)
+}
+
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// corsRoute is a route with a CORS policy.
+type corsRoute struct {
+	Method string
+	Policy *corsPolicy
+}
+
+// registerCORSRoute records the policy of a route, the innermost one of its
+// groups. Policies are kept apart from the route registry, which may be
+// disabled.
+func (engine *Engine) registerCORSRoute(method, path string, meta RouteMeta) {
+	policy, ok := meta["cors"].(*corsPolicy)
+	if !ok {
+		return
+	}
+
+	engine.corsRoutesMu.Lock()
+	defer engine.corsRoutesMu.Unlock()
+
+	if engine.corsRoutes == nil {
+		engine.corsRoutes = make(map[handlerRouteKey]*corsPolicy)
+	}
+	engine.corsRoutes[handlerRouteKey{Method: method, Path: path}] = policy
+}
+
+// corsRoutePolicy returns the policy of the route of method and path
+// template, or nil.
+func (engine *Engine) corsRoutePolicy(method, path string) *corsPolicy {
+	engine.corsRoutesMu.RLock()
+	defer engine.corsRoutesMu.RUnlock()
+	return engine.corsRoutes[handlerRouteKey{Method: method, Path: path}]
+}
+
+// matchCORSRoutes returns the routes with a policy whose path template
+// matches the request path, sorted by method.
+func (engine *Engine) matchCORSRoutes(path string) []corsRoute {
+	engine.corsRoutesMu.RLock()
+	defer engine.corsRoutesMu.RUnlock()
+
+	var routes []corsRoute
+	for key, policy := range engine.corsRoutes {
+		if routePathMatches(key.Path, path) {
+			routes = append(routes, corsRoute{Method: key.Method, Policy: policy})
+		}
+	}
+	slices.SortFunc(routes, func(a, b corsRoute) int {
+		return strings.Compare(a.Method, b.Method)
+	})
+	return routes
+}
+
+// corsPolicyFor returns the policy of the route of method, or of any route
+// of the path.
+func corsPolicyFor(routes []corsRoute, method string) *corsPolicy {
+	for _, route := range routes {
+		if strings.EqualFold(route.Method, method) {
+			return route.Policy
+		}
+	}
+	if len(routes) > 0 {
+		return routes[0].Policy
+	}
+	return nil
+}
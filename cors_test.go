@@ -0,0 +1,231 @@
+package fox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func corsRequest(t *testing.T, handler http.Handler, method, target, origin, requestMethod string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Origin", origin)
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouterGroupCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	require.NoError(t, router.CORS(cors.Config{AllowOrigins: []string{"https://www.example.com"}}))
+	router.GET("/status", func() string { return "ok" })
+
+	api := router.Group("/api")
+	require.NoError(t, api.CORS(cors.Config{
+		AllowOrigins:  []string{"https://app.example.com"},
+		ExposeHeaders: []string{"X-Total-Count"},
+		MaxAge:        time.Hour,
+	}))
+	api.GET("/users/:id", func() string { return "user" })
+	api.PUT("/users/:id", func() string { return "updated" })
+	api.DELETE("/users/:id", func() string { return "deleted" })
+
+	t.Run("preflight allows registered methods", func(t *testing.T) {
+		w := corsRequest(t, router, http.MethodOptions, "/api/users/1", "https://app.example.com", http.MethodPut)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "DELETE,GET,PUT", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("preflight rejects origins of other policies", func(t *testing.T) {
+		w := corsRequest(t, router, http.MethodOptions, "/api/users/1", "https://www.example.com", http.MethodPut)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("group policy replaces engine policy", func(t *testing.T) {
+		w := corsRequest(t, router, http.MethodGet, "/api/users/1", "https://app.example.com", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+
+		w = corsRequest(t, router, http.MethodGet, "/api/users/1", "https://www.example.com", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("engine policy applies outside the group", func(t *testing.T) {
+		w := corsRequest(t, router, http.MethodOptions, "/status", "https://www.example.com", http.MethodGet)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+
+		w = corsRequest(t, router, http.MethodGet, "/status", "https://www.example.com", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://www.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("unknown paths are not found", func(t *testing.T) {
+		w := corsRequest(t, router, http.MethodGet, "/missing", "https://www.example.com", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRouterGroupCORS_AllowMethodsLimitsPreflight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	require.NoError(t, router.CORS(cors.Config{
+		AllowOrigins: []string{"https://app.example.com"},
+		AllowMethods: []string{"GET", "POST"},
+	}))
+	router.GET("/items", func() string { return "items" })
+	router.POST("/items", func() string { return "created" })
+	router.DELETE("/items", func() string { return "deleted" })
+
+	w := corsRequest(t, router, http.MethodOptions, "/items", "https://app.example.com", http.MethodPost)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET,POST", w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestRouterGroupCORS_WithoutEnginePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	api := router.Group("/api")
+	require.NoError(t, api.CORS(cors.Config{AllowOrigins: []string{"https://app.example.com"}}))
+	api.POST("/orders", func() string { return "created" })
+
+	w := corsRequest(t, router, http.MethodOptions, "/api/orders", "https://app.example.com", http.MethodPost)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "POST", w.Header().Get("Access-Control-Allow-Methods"))
+
+	w = corsRequest(t, router, http.MethodOptions, "/other", "https://app.example.com", http.MethodPost)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRouterGroupCORS_WithoutRouteRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.DisableRouteRegistry()
+	require.NoError(t, router.CORS(cors.Config{AllowOrigins: []string{"https://www.example.com"}}))
+	api := router.Group("/api")
+	require.NoError(t, api.CORS(cors.Config{AllowOrigins: []string{"https://app.example.com"}}))
+	api.GET("/users/:id", func() string { return "user" })
+	api.PUT("/users/:id", func() string { return "updated" })
+	require.Empty(t, router.HandlerRoutes())
+
+	w := corsRequest(t, router, http.MethodGet, "/api/users/1", "https://app.example.com", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = corsRequest(t, router, http.MethodGet, "/api/users/1", "https://www.example.com", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = corsRequest(t, router, http.MethodOptions, "/api/users/1", "https://app.example.com", http.MethodPut)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET,PUT", w.Header().Get("Access-Control-Allow-Methods"))
+
+	w = corsRequest(t, router, http.MethodOptions, "/api/users/1", "https://www.example.com", http.MethodPut)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRouterGroupCORS_InvalidConfig(t *testing.T) {
+	router := New()
+	api := router.Group("/api")
+
+	err := api.CORS(cors.Config{})
+	require.Error(t, err)
+
+	api.GET("/users", func() string { return "users" })
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	assert.NotContains(t, routes[0].Meta, "cors")
+}
+
+func TestRouterGroupCORS_PerDomain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	de := NewDomainEngine(New)
+	de.Domain("api.example.com", func(sub *Engine) {
+		require.NoError(t, sub.CORS(cors.Config{AllowOrigins: []string{"https://app.example.com"}}))
+		sub.GET("/users", func() string { return "users" })
+	})
+	de.Domain("admin.example.com", func(sub *Engine) {
+		require.NoError(t, sub.CORS(cors.Config{AllowOrigins: []string{"https://admin.example.com"}}))
+		sub.GET("/users", func() string { return "users" })
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "http://api.example.com/users", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	de.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodOptions, "http://admin.example.com/users", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w = httptest.NewRecorder()
+	de.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRouterGroupCORS_Manifest(t *testing.T) {
+	router := New()
+	api := router.Group("/api")
+	require.NoError(t, api.CORS(cors.Config{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	api.GET("/users", func() string { return "users" })
+
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	data, err := json.Marshal(routes[0].Meta["cors"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"allowOrigins":["https://app.example.com"],"allowCredentials":true,"maxAge":"1h0m0s"}`, string(data))
+}
+
+func TestRoutePathMatches(t *testing.T) {
+	tests := []struct {
+		template, path string
+		want           bool
+	}{
+		{"/users", "/users", true},
+		{"/users", "/users/", false},
+		{"/users/:id", "/users/1", true},
+		{"/users/:id", "/users/", false},
+		{"/users/:id", "/users/1/posts", false},
+		{"/users/:id/posts", "/users/1/posts", true},
+		{"/users/:id/posts", "/users/1", false},
+		{"/files/*path", "/files/a/b", true},
+		{"/files/*path", "/files/", true},
+		{"/files/*path", "/other/a", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, routePathMatches(tt.template, tt.path), "%s %s", tt.template, tt.path)
+	}
+}
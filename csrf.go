@@ -0,0 +1,360 @@
+package fox
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+const (
+	// DefaultCSRFCookie is the cookie holding the token in double-submit mode.
+	DefaultCSRFCookie = "_csrf"
+
+	// DefaultCSRFHeader is the request header carrying the token.
+	DefaultCSRFHeader = "X-CSRF-Token"
+
+	// DefaultCSRFField is the form field carrying the token.
+	DefaultCSRFField = "csrf_token"
+)
+
+// csrfContextKey stores the *csrfState of the current request.
+const csrfContextKey = "_fox-gonic/fox/csrf"
+
+// csrfSessionKey stores the token in the session in synchronizer mode.
+const csrfSessionKey = "_csrf"
+
+// csrfTokenLength is the length of the raw token in bytes.
+const csrfTokenLength = 32
+
+var (
+	// ErrCSRFTokenInvalid is returned when an unsafe request carries no CSRF
+	// token or a wrong one.
+	ErrCSRFTokenInvalid = &httperrors.Error{
+		HTTPCode: http.StatusForbidden,
+		Err:      errors.New("invalid CSRF token"),
+		Code:     "CSRF_TOKEN_INVALID",
+	}
+
+	// ErrCSRFOriginInvalid is returned when the Origin or Referer of an unsafe
+	// request is not trusted.
+	ErrCSRFOriginInvalid = &httperrors.Error{
+		HTTPCode: http.StatusForbidden,
+		Err:      errors.New("untrusted request origin"),
+		Code:     "CSRF_ORIGIN_INVALID",
+	}
+)
+
+// CSRFMode is how the CSRF middleware keeps the expected token.
+type CSRFMode int
+
+const (
+	// CSRFDoubleSubmit keeps the token in a cookie that requests must echo in
+	// a header or form field. It needs no server-side state.
+	CSRFDoubleSubmit CSRFMode = iota
+
+	// CSRFSynchronizer keeps the token in the session, see Sessions.
+	CSRFSynchronizer
+)
+
+// String returns the mode name used in the route metadata.
+func (m CSRFMode) String() string {
+	if m == CSRFSynchronizer {
+		return "synchronizer"
+	}
+	return "double_submit"
+}
+
+// CSRFConfig defines the config for CSRF middleware.
+type CSRFConfig struct {
+	// Mode is how the token is kept, default is CSRFDoubleSubmit.
+	// Optional.
+	Mode CSRFMode
+
+	// Header carries the token, default is "X-CSRF-Token".
+	// Optional.
+	Header string
+
+	// Field is the form field carrying the token, default is "csrf_token".
+	// Optional.
+	Field string
+
+	// CookieName is the token cookie in double-submit mode, default is
+	// "_csrf". The cookie is readable by scripts so that they can echo it in
+	// Header.
+	// Optional.
+	CookieName string
+
+	// CookiePath of the token cookie, default is "/".
+	// Optional.
+	CookiePath string
+
+	// CookieDomain of the token cookie.
+	// Optional.
+	CookieDomain string
+
+	// Secure restricts the token cookie to HTTPS.
+	// Optional.
+	Secure bool
+
+	// TrustedOrigins are origins besides the request host allowed to send
+	// unsafe requests, for example "https://admin.example.com".
+	// Optional.
+	TrustedOrigins []string
+
+	// ExemptRoutes are route templates, optionally prefixed by a method such
+	// as "POST /webhooks/:provider", that are not checked.
+	// Optional.
+	ExemptRoutes []string
+
+	// Skip is called for unsafe requests; returning true skips the checks.
+	// Optional.
+	Skip func(c *gin.Context) bool
+}
+
+type csrfState struct {
+	token []byte
+	field string
+}
+
+// CSRF returns a middleware protecting cookie-authenticated routes against
+// cross-site request forgery. Unsafe requests (other than GET, HEAD, OPTIONS
+// and TRACE) must come from the request host or a trusted origin, as told by
+// the Origin or Referer header, and carry the token in the Header or the form
+// Field. Failures are answered with 403 ErrCSRFTokenInvalid or
+// ErrCSRFOriginInvalid.
+//
+// Templates get the token from Context.CSRFToken and Context.CSRFField, or
+// as the "csrfToken" and "csrfField" values of render.HTML data. The config
+// is recorded as the "csrf" route metadata.
+//
+//	admin := router.Group("/admin", fox.Sessions(sessions), fox.CSRF(fox.CSRFConfig{
+//		Mode:         fox.CSRFSynchronizer,
+//		ExemptRoutes: []string{"POST /admin/webhooks"},
+//	}))
+func CSRF(config CSRFConfig) *Middleware {
+	if config.Header == "" {
+		config.Header = DefaultCSRFHeader
+	}
+	if config.Field == "" {
+		config.Field = DefaultCSRFField
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultCSRFCookie
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+
+	meta := map[string]any{
+		"mode":   config.Mode.String(),
+		"header": config.Header,
+		"field":  config.Field,
+	}
+	if config.Mode == CSRFDoubleSubmit {
+		meta["cookie"] = config.CookieName
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			var token []byte
+			if config.Mode == CSRFSynchronizer {
+				session := SessionFromContext(c)
+				if session == nil {
+					_ = c.Error(errors.New("fox: CSRF synchronizer mode requires the Sessions middleware"))
+					AbortWithError(c, httperrors.ErrInternalServerError)
+					return
+				}
+				token = csrfSessionToken(session)
+			} else {
+				token = csrfCookieToken(c, config)
+			}
+
+			state := &csrfState{token: token, field: config.Field}
+			c.Set(csrfContextKey, state)
+			SetTemplateData(c, "csrfToken", maskCSRFToken(token))
+			SetTemplateData(c, "csrfField", state.formField())
+
+			if csrfSafeMethod(c.Request.Method) || csrfExempt(c, config) {
+				return
+			}
+			if !csrfTrustedOrigin(c, config.TrustedOrigins) {
+				AbortWithError(c, ErrCSRFOriginInvalid)
+				return
+			}
+
+			submitted := c.GetHeader(config.Header)
+			if submitted == "" && csrfFormRequest(c) {
+				submitted = c.PostForm(config.Field)
+			}
+			if !validCSRFToken(token, submitted) {
+				AbortWithError(c, ErrCSRFTokenInvalid)
+			}
+		},
+		Meta: RouteMeta{"csrf": meta},
+	}
+}
+
+// CSRFToken returns the CSRF token to embed in a page or send from scripts,
+// or "" without the CSRF middleware. Each call returns a different masked
+// form of the same token, so that it does not leak through compressed
+// responses (BREACH).
+func (c *Context) CSRFToken() string {
+	if state := csrfFromContext(c.Context); state != nil {
+		return maskCSRFToken(state.token)
+	}
+	return ""
+}
+
+// CSRFField returns a hidden form input carrying the CSRF token, or "" without
+// the CSRF middleware.
+func (c *Context) CSRFField() template.HTML {
+	if state := csrfFromContext(c.Context); state != nil {
+		return state.formField()
+	}
+	return ""
+}
+
+func csrfFromContext(c *gin.Context) *csrfState {
+	if v, exists := c.Get(csrfContextKey); exists {
+		state, _ := v.(*csrfState)
+		return state
+	}
+	return nil
+}
+
+func (s *csrfState) formField() template.HTML {
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(s.field) +
+		`" value="` + maskCSRFToken(s.token) + `">`)
+}
+
+func csrfSessionToken(session *Session) []byte {
+	if encoded, ok := session.Get(csrfSessionKey).(string); ok {
+		if token, err := base64.RawURLEncoding.DecodeString(encoded); err == nil && len(token) == csrfTokenLength {
+			return token
+		}
+	}
+	token := newCSRFToken()
+	session.Set(csrfSessionKey, base64.RawURLEncoding.EncodeToString(token))
+	return token
+}
+
+func csrfCookieToken(c *gin.Context, config CSRFConfig) []byte {
+	if encoded, err := c.Cookie(config.CookieName); err == nil {
+		if token, err := base64.RawURLEncoding.DecodeString(encoded); err == nil && len(token) == csrfTokenLength {
+			return token
+		}
+	}
+	token := newCSRFToken()
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.CookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(token),
+		Path:     config.CookiePath,
+		Domain:   config.CookieDomain,
+		Secure:   config.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token
+}
+
+func newCSRFToken() []byte {
+	token := make([]byte, csrfTokenLength)
+	_, _ = rand.Read(token)
+	return token
+}
+
+// maskCSRFToken returns a random one-time pad followed by the token XORed
+// with it.
+func maskCSRFToken(token []byte) string {
+	masked := make([]byte, 2*len(token))
+	pad := masked[:len(token)]
+	_, _ = rand.Read(pad)
+	subtle.XORBytes(masked[len(token):], token, pad)
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// validCSRFToken compares a submitted token, masked or as stored in the
+// double-submit cookie, with the expected token.
+func validCSRFToken(token []byte, submitted string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(submitted)
+	if err != nil {
+		return false
+	}
+	switch len(decoded) {
+	case csrfTokenLength:
+	case 2 * csrfTokenLength:
+		subtle.XORBytes(decoded[csrfTokenLength:], decoded[csrfTokenLength:], decoded[:csrfTokenLength])
+		decoded = decoded[csrfTokenLength:]
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(token, decoded) == 1
+}
+
+func csrfSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func csrfExempt(c *gin.Context, config CSRFConfig) bool {
+	route := c.FullPath()
+	for _, exempt := range config.ExemptRoutes {
+		if method, path, ok := strings.Cut(exempt, " "); ok {
+			if method == c.Request.Method && path == route {
+				return true
+			}
+		} else if exempt == route {
+			return true
+		}
+	}
+	return config.Skip != nil && config.Skip(c)
+}
+
+// csrfTrustedOrigin checks the Origin header, or the Referer when there is
+// none, against the request host and the trusted origins. Requests with
+// neither header are left to the token check.
+func csrfTrustedOrigin(c *gin.Context, trusted []string) bool {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		referer := c.GetHeader("Referer")
+		if referer == "" {
+			return true
+		}
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, c.Request.Host) {
+		return true
+	}
+	return slices.ContainsFunc(trusted, func(o string) bool {
+		return strings.EqualFold(strings.TrimSuffix(o, "/"), origin)
+	})
+}
+
+func csrfFormRequest(c *gin.Context) bool {
+	switch c.ContentType() {
+	case gin.MIMEPOSTForm, gin.MIMEMultipartPOSTForm:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package fox
+
+import (
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/render"
+)
+
+var csrfFormValue = regexp.MustCompile(`value="([^"]+)"`)
+
+func csrfRequest(router http.Handler, method, path string, body string, header map[string]string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "example.com"
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCSRF_DoubleSubmit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(CSRF(CSRFConfig{ExemptRoutes: []string{"POST /webhooks"}}))
+	router.GET("/form", func(c *Context) string { return c.CSRFToken() })
+	router.POST("/submit", func() string { return "ok" })
+	router.POST("/webhooks", func() string { return "ok" })
+
+	w := csrfRequest(router, http.MethodGet, "/form", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	cookie := findCookie(w, DefaultCSRFCookie)
+	require.NotNil(t, cookie)
+	assert.False(t, cookie.HttpOnly)
+	token := w.Body.String()
+	assert.NotEqual(t, cookie.Value, token, "exposed tokens are masked")
+
+	tests := []struct {
+		name    string
+		body    string
+		header  map[string]string
+		cookies []*http.Cookie
+		status  int
+		code    string
+	}{
+		{"masked header", "", map[string]string{DefaultCSRFHeader: token}, []*http.Cookie{cookie}, http.StatusOK, ""},
+		{"cookie echoed", "", map[string]string{DefaultCSRFHeader: cookie.Value}, []*http.Cookie{cookie}, http.StatusOK, ""},
+		{"form field", url.Values{DefaultCSRFField: {token}}.Encode(),
+			map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, []*http.Cookie{cookie}, http.StatusOK, ""},
+		{"missing token", "", nil, []*http.Cookie{cookie}, http.StatusForbidden, "CSRF_TOKEN_INVALID"},
+		{"missing cookie", "", map[string]string{DefaultCSRFHeader: token}, nil, http.StatusForbidden, "CSRF_TOKEN_INVALID"},
+		{"wrong token", "", map[string]string{DefaultCSRFHeader: "bm9wZQ"}, []*http.Cookie{cookie}, http.StatusForbidden, "CSRF_TOKEN_INVALID"},
+		{"same origin", "", map[string]string{DefaultCSRFHeader: token, "Origin": "https://example.com"}, []*http.Cookie{cookie}, http.StatusOK, ""},
+		{"cross origin", "", map[string]string{DefaultCSRFHeader: token, "Origin": "https://evil.test"}, []*http.Cookie{cookie}, http.StatusForbidden, "CSRF_ORIGIN_INVALID"},
+		{"null origin", "", map[string]string{DefaultCSRFHeader: token, "Origin": "null"}, []*http.Cookie{cookie}, http.StatusForbidden, "CSRF_ORIGIN_INVALID"},
+		{"cross referer", "", map[string]string{DefaultCSRFHeader: token, "Referer": "https://evil.test/page"}, []*http.Cookie{cookie}, http.StatusForbidden, "CSRF_ORIGIN_INVALID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := csrfRequest(router, http.MethodPost, "/submit", tt.body, tt.header, tt.cookies...)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.code != "" {
+				assert.Contains(t, w.Body.String(), tt.code)
+			}
+		})
+	}
+
+	w = csrfRequest(router, http.MethodPost, "/webhooks", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRF_TrustedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.POST("/submit", CSRF(CSRFConfig{TrustedOrigins: []string{"https://admin.example.org/"}}), func() string { return "ok" })
+
+	token := newCSRFToken()
+	cookie := &http.Cookie{Name: DefaultCSRFCookie, Value: base64.RawURLEncoding.EncodeToString(token)}
+	header := map[string]string{DefaultCSRFHeader: maskCSRFToken(token), "Origin": "https://admin.example.org"}
+	w := csrfRequest(router, http.MethodPost, "/submit", "", header, cookie)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRF_Synchronizer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tmpl := template.Must(template.New("form").Parse(`<form>{{.csrfField}}<p>{{.title}}</p></form>`))
+
+	router := New()
+	router.Use(Sessions(SessionConfig{Store: NewMemorySessionStore()}), CSRF(CSRFConfig{Mode: CSRFSynchronizer}))
+	router.GET("/form", func() render.HTML {
+		return render.HTML{Template: tmpl, Name: "form", Data: gin.H{"title": "Edit"}}
+	})
+	router.POST("/submit", func() string { return "ok" })
+
+	w := csrfRequest(router, http.MethodGet, "/form", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, findCookie(w, DefaultCSRFCookie))
+	session := findCookie(w, DefaultSessionCookie)
+	require.NotNil(t, session)
+	assert.Contains(t, w.Body.String(), `<input type="hidden" name="csrf_token"`)
+	assert.Contains(t, w.Body.String(), `<p>Edit</p>`)
+	match := csrfFormValue.FindStringSubmatch(w.Body.String())
+	require.Len(t, match, 2)
+
+	form := url.Values{DefaultCSRFField: {match[1]}}.Encode()
+	formHeader := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	w = csrfRequest(router, http.MethodPost, "/submit", form, formHeader, session)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The token is bound to the session.
+	w = csrfRequest(router, http.MethodPost, "/submit", form, formHeader)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "CSRF_TOKEN_INVALID")
+}
+
+func TestCSRF_SynchronizerRequiresSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/", CSRF(CSRFConfig{Mode: CSRFSynchronizer}), func() string { return "ok" })
+
+	w := csrfRequest(router, http.MethodGet, "/", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
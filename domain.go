@@ -1,11 +1,22 @@
 package fox
 
 import (
+	"context"
 	"net/http"
 	"regexp"
 	"strings"
 )
 
+type domainContextKey struct{}
+
+// DomainFromContext returns the domain pattern a DomainEngine dispatched the
+// request to, or an empty string when the request was served by the default
+// engine or without a DomainEngine.
+func DomainFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(domainContextKey{}).(string)
+	return name
+}
+
 type domain struct {
 	Name     string
 	IsRegexp bool
@@ -98,10 +109,8 @@ func (engine *DomainEngine) ServeHTTP(w http.ResponseWriter, req *http.Request)
 
 	for i := 0; i < len(engine.domains); i++ {
 		domain := engine.domains[i]
-		if domain.IsRegexp && domain.Regexp.MatchString(host) {
-			domain.Handler.ServeHTTP(w, req)
-			return
-		} else if domain.Name == host {
+		if (domain.IsRegexp && domain.Regexp.MatchString(host)) || domain.Name == host {
+			req = req.WithContext(context.WithValue(req.Context(), domainContextKey{}, domain.Name))
 			domain.Handler.ServeHTTP(w, req)
 			return
 		}
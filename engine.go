@@ -24,6 +24,9 @@ const (
 	TestMode = gin.TestMode
 )
 
+// engineContextKey stores the *Engine serving the request.
+const engineContextKey = "_fox-gonic/fox/engine"
+
 var foxMode = DebugMode
 
 // SetMode sets gin mode according to input string.
@@ -59,10 +62,14 @@ var MsgInvalidHandlerType = "invalid handler type: %s\n" +
 	"3. func(ctx *Context) (T, error)\n" +
 	"4. func(ctx *Context, args S) T\n" +
 	"5. func(ctx *Context, args S) (T, error)\n" +
+	"6. func(ctx *Context, services P..., params B..., args S) (T, error)\n" +
 	"Where:\n" +
 	"- S can be struct or map type, S will be auto binding from request body\n" +
+	"- B can be struct or map types implementing BindingSourcer, B will be auto binding from their source\n" +
+	"- P can be any type provided by Engine.Provide, P will be injected\n" +
 	"- T can be any type, T will be auto render to response body\n" +
-	"- error can be any type that implements error interface"
+	"- error can be any type that implements error interface\n" +
+	"- ctx can also be a context.Context, see FromContext"
 
 // Deprecated: Use MsgInvalidHandlerType. This alias will be removed in v0.2.0.
 var ErrInvalidHandlerType = MsgInvalidHandlerType
@@ -76,11 +83,17 @@ var ErrInvalidHandlerType = MsgInvalidHandlerType
 //  3. func(ctx *Context) (T, error) { ... }
 //  4. func(ctx *Context, args S) T { ... }
 //  5. func(ctx *Context, args S) (T, error) { ... }
+//  6. func(ctx *Context, services P..., params B..., args S) (T, error) { ... }
 //
 // Where:
 //   - S can be struct or map type, S will be auto binding from request body
+//   - B can be struct or map types implementing BindingSourcer, B will be auto
+//     binding from their source
+//   - P can be any type provided by Engine.Provide, P will be injected
 //   - T can be any type, T will be auto render to response body
 //   - error can be any type that implements error interface
+//   - ctx can also be a context.Context, the request context carrying the
+//     *Context, see FromContext
 //
 // IMPORTANT: When a handler with a non-nil return value is used as middleware
 // through Use, the chain is aborted after the value is rendered. For middleware
@@ -114,9 +127,59 @@ type Engine struct {
 
 	RenderErrorFunc RenderErrorFunc
 
+	// Pagination defines the limits of Page parameters and the rendering of
+	// Paginated results.
+	// Optional.
+	Pagination PaginationConfig
+
+	// Envelope wraps the JSON bodies of handler results and errors, such as
+	// DefaultEnvelope. The route manifest describes the wrapped bodies.
+	// Optional.
+	Envelope Envelope
+
+	// MaxBodySize is the default maximum request body size in bytes enforced
+	// by fox handlers, DefaultMaxBodySize unless changed. Zero disables the
+	// limit. BodyLimit overrides it per group or route.
+	MaxBodySize int64
+
+	// MaxBodySizeByContentType sets the default maximum request body size per
+	// media type, for example "multipart/form-data", and takes precedence
+	// over MaxBodySize.
+	MaxBodySizeByContentType map[string]int64
+
+	// HandleOptions answers OPTIONS requests for paths without an OPTIONS
+	// route with 204 and an Allow header listing the methods registered for
+	// the path. Enabled by New; register an OPTIONS route to override it for
+	// a path.
+	HandleOptions bool
+
 	handlerRoutesMu       sync.RWMutex
 	handlerRoutes         map[handlerRouteKey]RouteInfo
 	handlerRoutesDisabled atomic.Bool
+
+	// corsRoutes are the CORS policies of routes, kept without the route
+	// registry.
+	corsRoutesMu sync.RWMutex
+	corsRoutes   map[handlerRouteKey]*corsPolicy
+
+	// routeMethods are the methods of routes by path template, kept without
+	// the route registry for the Allow header. paramRoutes lists the
+	// templates with parameters, which are matched one by one.
+	routeMethodsMu sync.RWMutex
+	routeMethods   map[string][]string
+	paramRoutes    []string
+
+	// versionedRoutes are the unversioned routes of versioned routes.
+	versionedRoutes map[handlerRouteKey]bool
+
+	// boundParams are the handler parameter types bound from the request,
+	// by handler name, which cannot be provided afterwards.
+	boundParams map[reflect.Type]string
+
+	healthOnce sync.Once
+	health     *Health
+
+	services *container
 }
 
 // DisableRouteRegistry stops collecting handler reflection metadata for new
@@ -139,14 +202,37 @@ func New() *Engine {
 	engine := &Engine{
 		Engine:                       gin.New(),
 		DefaultRenderErrorStatusCode: http.StatusBadRequest,
+		MaxBodySize:                  DefaultMaxBodySize,
+		HandleOptions:                true,
 	}
 
 	// recommend default use context.Context to store request-scoped values
 	engine.ContextWithFallback = true
 
+	// answer requests with a wrong method with 405 and an Allow header
+	engine.HandleMethodNotAllowed = true
+
 	engine.router = &engine.Engine.RouterGroup
 	engine.engine = engine
 
+	// let gin middleware render errors like fox handlers, see AbortWithError
+	engine.Engine.Use(engine.setContext)
+
+	engine.Engine.NoRoute(engine.handleFallback)
+	engine.Engine.NoMethod(engine.handleFallback)
+
+	return engine
+}
+
+// setContext records the engine serving the request.
+func (engine *Engine) setContext(c *gin.Context) {
+	c.Set(engineContextKey, engine)
+}
+
+// engineFromContext returns the engine serving the request, or nil.
+func engineFromContext(c *gin.Context) *Engine {
+	v, _ := c.Get(engineContextKey)
+	engine, _ := v.(*Engine)
 	return engine
 }
 
@@ -164,25 +250,32 @@ func (engine *Engine) Use(middleware ...HandlerFunc) {
 
 // NotFound adds handlers for NoRoute. It returns a 404 code by default.
 func (engine *Engine) NotFound(handlers ...HandlerFunc) {
-	handlersChain := engine.handleWrapper(handlers...)
-	engine.Engine.NoRoute(handlersChain...)
+	engine.NoRoute(handlers...)
 }
 
 func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
-	handlersChain := engine.handleWrapper(handlers...)
+	handlersChain := append(gin.HandlersChain{engine.handleFallback}, engine.wrapHandlers(handlers, true)...)
 	engine.Engine.NoRoute(handlersChain...)
 }
 
 func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
-	handlersChain := engine.handleWrapper(handlers...)
+	handlersChain := append(gin.HandlersChain{engine.handleFallback}, engine.wrapHandlers(handlers, true)...)
 	engine.Engine.NoMethod(handlersChain...)
 }
 
-// CORS config.
-func (engine *Engine) CORS(config cors.Config) {
-	if config.Validate() == nil {
-		engine.Engine.Use(cors.New(config))
+// CORS attaches a CORS policy to all routes registered after the call and to
+// unmatched requests. Groups may replace it with RouterGroup.CORS, and the
+// engines of DomainEngine.Domain with their own Engine.CORS. It returns the
+// error of config.Validate instead of attaching an invalid policy.
+func (engine *Engine) CORS(config cors.Config) error {
+	policy, err := newCORSPolicy(engine, config)
+	if err != nil {
+		return err
 	}
+	middleware := policy.middleware()
+	engine.meta = mergeRouteMeta(engine.meta, []HandlerFunc{middleware})
+	engine.Engine.Use(middleware.Handler)
+	return nil
 }
 
 // RouterConfigFunc engine load router config func.
@@ -195,16 +288,21 @@ func (engine *Engine) Load(f RouterConfigFunc, fs ...embed.FS) {
 
 // IsValidHandlerFunc checks if the handler matches the HandlerFunc type requirements.
 func IsValidHandlerFunc(handler HandlerFunc) bool {
+	return isValidHandlerFunc(handler, nil)
+}
+
+// isValidHandlerFunc is IsValidHandlerFunc also accepting parameters of the
+// types provided by services.
+func isValidHandlerFunc(handler HandlerFunc, services *container) bool {
 	handlerType := reflect.TypeOf(handler)
 
-	// Check if it's a function typ
-	if handlerType.Kind() != reflect.Func {
-		return false
+	// Middleware carries a gin.HandlerFunc
+	if m, ok := handler.(*Middleware); ok {
+		return m != nil && m.Handler != nil
 	}
 
-	// Check number of parameters
-	numIn := handlerType.NumIn()
-	if numIn > 2 {
+	// Check if it's a function typ
+	if handlerType.Kind() != reflect.Func {
 		return false
 	}
 
@@ -214,23 +312,37 @@ func IsValidHandlerFunc(handler HandlerFunc) bool {
 		return false
 	}
 
-	// Check if first parameter is *Context
+	// Check if first parameter is *Context or context.Context
+	numIn := handlerType.NumIn()
 	if numIn > 0 {
 		firstParam := handlerType.In(0)
-		if firstParam.Kind() != reflect.Ptr || firstParam.Elem().Name() != "Context" {
+		if firstParam != stdContextType && (firstParam.Kind() != reflect.Ptr || firstParam.Elem().Name() != "Context") {
 			return false
 		}
 	}
 
-	// Check if second parameter is struct or map type
-	if numIn > 1 {
-		secondParam := handlerType.In(1)
+	// Check that other parameters are provided services, struct or map types
+	// bound from a single source, or one struct or map type bound from the
+	// whole request
+	bound := 0
+	for i := 1; i < numIn; i++ {
+		param := handlerType.In(i)
+		if services.provides(param) {
+			continue
+		}
+		switch bindingSourceOf(param) {
+		case SourcePath, SourceQuery, SourceHeader, SourceBody:
+		case "":
+			bound++
+		default:
+			return false
+		}
 		// If it's a pointer type, get the type it points to
-		if secondParam.Kind() == reflect.Ptr {
-			secondParam = secondParam.Elem()
+		if param.Kind() == reflect.Ptr {
+			param = param.Elem()
 		}
 		// Check if it's a struct or map type
-		if secondParam.Kind() != reflect.Struct && secondParam.Kind() != reflect.Map {
+		if bound > 1 || param.Kind() != reflect.Struct && param.Kind() != reflect.Map {
 			return false
 		}
 	}
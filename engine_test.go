@@ -583,12 +583,13 @@ func TestEngine_CORS(t *testing.T) {
 		router := fox.New()
 
 		// Configure CORS with valid settings - must be called before routes
-		router.CORS(cors.Config{
+		err := router.CORS(cors.Config{
 			AllowOrigins:     []string{"http://example.com"},
 			AllowMethods:     []string{"GET", "POST"},
 			AllowHeaders:     []string{"Origin", "Content-Type"},
 			AllowCredentials: true,
 		})
+		require.NoError(t, err)
 
 		router.GET("/test", func() string {
 			return "test"
@@ -604,17 +605,18 @@ func TestEngine_CORS(t *testing.T) {
 		})
 	})
 
-	t.Run("invalid CORS config should not panic", func(t *testing.T) {
+	t.Run("invalid CORS config returns an error", func(t *testing.T) {
 		// Test that invalid CORS config doesn't cause panic
 		assert.NotPanics(t, func() {
 			router := fox.New()
 
 			// Configure CORS with invalid settings (this should not apply CORS middleware)
-			router.CORS(cors.Config{
+			err := router.CORS(cors.Config{
 				AllowAllOrigins:  true,
 				AllowOrigins:     []string{"http://example.com"}, // Invalid: can't set both
 				AllowCredentials: true,
 			})
+			assert.Error(t, err)
 
 			router.GET("/test", func() string {
 				return "test"
@@ -632,10 +634,11 @@ func TestEngine_CORS(t *testing.T) {
 	t.Run("CORS with wildcard origin", func(t *testing.T) {
 		router := fox.New()
 
-		router.CORS(cors.Config{
+		err := router.CORS(cors.Config{
 			AllowAllOrigins: true,
 			AllowMethods:    []string{"GET", "POST", "PUT", "DELETE"},
 		})
+		require.NoError(t, err)
 
 		router.GET("/test", func() string {
 			return "test"
@@ -0,0 +1,133 @@
+package fox
+
+import (
+	"maps"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/render"
+)
+
+// envelopeMetaContextKey stores the values added by SetEnvelopeMeta.
+const envelopeMetaContextKey = "_fox-gonic/fox/envelope-meta"
+
+// Envelope wraps the JSON bodies of the results and errors of fox handlers,
+// so that handlers keep returning plain values:
+//
+//	router := fox.New()
+//	router.Envelope = fox.DefaultEnvelope{}
+//
+// Strings, render.Render values, redirects and render.Render errors are
+// rendered as is, as are errors when Engine.RenderErrorFunc is set.
+type Envelope interface {
+	// Wrap returns the body rendered for a handler result.
+	Wrap(c *Context, data any) any
+
+	// WrapError returns the body rendered for a handler error, body being
+	// the one rendered without envelope.
+	WrapError(c *Context, err error, body any) any
+
+	// Type returns the type of the body rendered for results of type data,
+	// or for errors when data implements error. It describes the wrapped
+	// results in the route manifest.
+	Type(data reflect.Type) reflect.Type
+}
+
+// DefaultEnvelope renders results as
+//
+//	{"data": ..., "meta": {...}, "request_id": "..."}
+//
+// and errors as
+//
+//	{"error": {"code": "...", "error": "..."}, "meta": {...}, "request_id": "..."}
+//
+// The meta holds the values of SetEnvelopeMeta, such as pagination, and the
+// timings in milliseconds when the ServerTiming middleware is installed. The
+// request ID is Context.TraceID.
+type DefaultEnvelope struct{}
+
+// Wrap implements Envelope.
+func (DefaultEnvelope) Wrap(c *Context, data any) any {
+	return defaultEnvelopeData{Data: data, Meta: defaultEnvelopeMeta(c), RequestID: c.TraceID()}
+}
+
+// WrapError implements Envelope.
+func (DefaultEnvelope) WrapError(c *Context, err error, body any) any {
+	return defaultEnvelopeError{Error: body, Meta: defaultEnvelopeMeta(c), RequestID: c.TraceID()}
+}
+
+// Type implements Envelope.
+func (DefaultEnvelope) Type(data reflect.Type) reflect.Type {
+	field := reflect.StructField{Name: "Data", Type: data, Tag: `json:"data"`}
+	if data.Implements(errorType) {
+		field = reflect.StructField{Name: "Error", Type: data, Tag: `json:"error"`}
+	}
+	return reflect.StructOf([]reflect.StructField{
+		field,
+		{Name: "Meta", Type: reflect.TypeFor[map[string]any](), Tag: `json:"meta,omitempty"`},
+		{Name: "RequestID", Type: reflect.TypeFor[string](), Tag: `json:"request_id"`},
+	})
+}
+
+// defaultEnvelopeData is the body rendered by DefaultEnvelope for results.
+type defaultEnvelopeData struct {
+	Data      any            `json:"data"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	RequestID string         `json:"request_id"`
+}
+
+// defaultEnvelopeError is the body rendered by DefaultEnvelope for errors.
+type defaultEnvelopeError struct {
+	Error     any            `json:"error"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	RequestID string         `json:"request_id"`
+}
+
+func defaultEnvelopeMeta(c *Context) map[string]any {
+	meta := maps.Clone(EnvelopeMetaFromContext(c.Context))
+	if timing := serverTimingFromContext(c.Context); timing != nil {
+		metrics := timing.snapshot()
+		timings := make(map[string]any, len(metrics))
+		for _, metric := range metrics {
+			timings[metric.Name] = float64(metric.Duration.Microseconds()) / 1000
+		}
+		if meta == nil {
+			meta = make(map[string]any, 1)
+		}
+		meta["timing"] = timings
+	}
+	return meta
+}
+
+// SetEnvelopeMeta adds a value to the meta of the Envelope of the request,
+// for example pagination details.
+func SetEnvelopeMeta(c *gin.Context, key string, value any) {
+	v, _ := c.Get(envelopeMetaContextKey)
+	values, _ := v.(map[string]any)
+	if values == nil {
+		values = make(map[string]any)
+		c.Set(envelopeMetaContextKey, values)
+	}
+	values[key] = value
+}
+
+// EnvelopeMetaFromContext returns the values added by SetEnvelopeMeta, or
+// nil.
+func EnvelopeMetaFromContext(c *gin.Context) map[string]any {
+	if c == nil {
+		return nil
+	}
+	v, _ := c.Get(envelopeMetaContextKey)
+	values, _ := v.(map[string]any)
+	return values
+}
+
+// envelopedType returns the type rendered for a handler result type, which
+// is wrapped by envelope unless rendered as is.
+func envelopedType(envelope Envelope, typ reflect.Type) reflect.Type {
+	if typ == reflect.TypeFor[string]() || typ.Implements(reflect.TypeFor[render.Render]()) {
+		return typ
+	}
+	return envelope.Type(typ)
+}
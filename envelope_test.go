@@ -0,0 +1,183 @@
+package fox
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+	"github.com/fox-gonic/fox/logger"
+)
+
+func newEnvelopeRouter() *Engine {
+	router := New()
+	router.Envelope = DefaultEnvelope{}
+	router.GET("/users/:id", func(c *Context) (*testUser, error) {
+		SetEnvelopeMeta(c.Context, "cached", true)
+		return &testUser{ID: c.Param("id"), Name: "gopher"}, nil
+	})
+	router.GET("/missing", func(c *Context) (*testUser, error) {
+		return nil, httperrors.ErrNotFound
+	})
+	router.GET("/failed", func(c *Context) (*testUser, error) {
+		return nil, errors.New("failed")
+	})
+	router.GET("/text", func(c *Context) string { return "plain" })
+	router.POST("/users", func(c *Context) Response[*testUser] {
+		return Created("/users/1", &testUser{ID: "1"})
+	})
+	router.DELETE("/users/:id", func(c *Context) Response[any] { return NoContent() })
+	return router
+}
+
+// assertEnvelopeJSON compares the body with expected, whose request ID is
+// "{id}".
+func assertEnvelopeJSON(t *testing.T, expected string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	id := w.Header().Get(logger.TraceID)
+	require.NotEmpty(t, id)
+	assert.JSONEq(t, strings.ReplaceAll(expected, "{id}", id), w.Body.String())
+}
+
+func TestEnvelope_Render(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newEnvelopeRouter()
+
+	t.Run("result", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/users/7", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertEnvelopeJSON(t, `{"data":{"id":"7","name":"gopher"},"meta":{"cached":true},"request_id":"{id}"}`, w)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		w := serve(router, http.MethodPost, "/users", "")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assertEnvelopeJSON(t, `{"data":{"id":"1","name":""},"request_id":"{id}"}`, w)
+
+		w = serve(router, http.MethodDelete, "/users/1", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/missing", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, w.Header().Get(logger.TraceID), body["request_id"])
+		assert.Equal(t, "NOT_FOUND", body["error"].(map[string]any)["code"])
+
+		w = serve(router, http.MethodGet, "/failed", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertEnvelopeJSON(t, `{"error":"failed","request_id":"{id}"}`, w)
+	})
+
+	t.Run("strings are not wrapped", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/text", "")
+		assert.Equal(t, "plain", w.Body.String())
+	})
+
+	t.Run("render error func wins", func(t *testing.T) {
+		router := newEnvelopeRouter()
+		router.RenderErrorFunc = func(c *Context, err error) { c.String(http.StatusTeapot, "custom") }
+		w := serve(router, http.MethodGet, "/failed", "")
+		assert.Equal(t, http.StatusTeapot, w.Code)
+		assert.Equal(t, "custom", w.Body.String())
+	})
+}
+
+func TestEnvelope_MiddlewareErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Envelope = DefaultEnvelope{}
+	router.GET("/admin", RequireRoles("admin"), func(c *Context) string { return "ok" })
+	versions := router.Group("/api").Versioning(VersioningConfig{Header: DefaultVersionHeader})
+	versions.Version("1").GET("/users", func(c *Context) string { return "v1" })
+
+	t.Run("authorization", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/admin", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assertEnvelopeJSON(t, `{"error":{"code":"UNAUTHORIZED","error":"(401): unauthorized","meta":"unauthorized"},"request_id":"{id}"}`, w)
+	})
+
+	t.Run("versioning", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/users", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertEnvelopeJSON(t, `{"error":{"code":"API_VERSION_UNSUPPORTED","error":"(400): unsupported API version","meta":"unsupported API version"},"request_id":"{id}"}`, w)
+	})
+
+	t.Run("render error func wins", func(t *testing.T) {
+		router.RenderErrorFunc = func(c *Context, err error) { c.String(http.StatusTeapot, err.Error()) }
+		w := serve(router, http.MethodGet, "/admin", "")
+		assert.Equal(t, http.StatusTeapot, w.Code)
+		assert.Equal(t, "(401): unauthorized", w.Body.String())
+	})
+}
+
+func TestEnvelope_ServerTiming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Envelope = DefaultEnvelope{}
+	router.Use(ServerTiming())
+	router.GET("/", func(c *Context) map[string]int { return map[string]int{"n": 1} })
+
+	w := serve(router, http.MethodGet, "/", "")
+	var body struct {
+		Meta struct {
+			Timing map[string]float64 `json:"timing"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Contains(t, body.Meta.Timing, ServerTimingHandler)
+	assert.Contains(t, body.Meta.Timing, ServerTimingTotal)
+}
+
+func TestEnvelope_RouteManifest(t *testing.T) {
+	router := newEnvelopeRouter()
+
+	results := map[string][]RouteManifestType{}
+	for _, route := range RouteManifestFromEngine(router).Routes {
+		results[route.Method+" "+route.Path] = route.ResultTypes
+	}
+
+	user := results["GET /users/:id"]
+	require.Len(t, user, 2)
+	require.Len(t, user[0].Fields, 3)
+	assert.Equal(t, "Data", user[0].Fields[0].Name)
+	assert.Equal(t, `json:"data"`, user[0].Fields[0].Tag)
+	assert.Equal(t, "testUser", user[0].Fields[0].Type.Elem.Name)
+	assert.Equal(t, "Error", user[1].Fields[0].Name)
+
+	created := results["POST /users"]
+	require.Len(t, created, 1)
+	assert.Equal(t, "testUser", created[0].Fields[0].Type.Elem.Name)
+
+	assert.Equal(t, "string", results["GET /text"][0].Kind)
+}
+
+func TestDefaultEnvelope_Type(t *testing.T) {
+	typ := DefaultEnvelope{}.Type(reflect.TypeFor[testUser]())
+	data, err := json.Marshal(reflect.New(typ).Elem().Interface())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":{"id":"","name":""},"request_id":""}`, string(data))
+}
+
+func TestEnvelopeMetaFromContext(t *testing.T) {
+	assert.Nil(t, EnvelopeMetaFromContext(nil))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Nil(t, EnvelopeMetaFromContext(c))
+	SetEnvelopeMeta(c, "page", 2)
+	assert.Equal(t, map[string]any{"page": 2}, EnvelopeMetaFromContext(c))
+}
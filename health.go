@@ -0,0 +1,303 @@
+package fox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultLivenessPath is the default path of the liveness endpoint.
+	DefaultLivenessPath = "/healthz"
+	// DefaultReadinessPath is the default path of the readiness endpoint.
+	DefaultReadinessPath = "/readyz"
+	// DefaultHealthCheckTimeout is the default timeout for a single check.
+	DefaultHealthCheckTimeout = 5 * time.Second
+)
+
+// Health statuses reported by the liveness and readiness endpoints.
+const (
+	HealthStatusOK           = "ok"
+	HealthStatusDegraded     = "degraded"
+	HealthStatusFail         = "fail"
+	HealthStatusShuttingDown = "shutting_down"
+)
+
+// HealthCheckFunc reports the health of a dependency. A nil error means the
+// dependency is healthy. The context is canceled when the check times out.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck is a named check run by the readiness endpoint, and by the
+// liveness endpoint as well when Liveness is set.
+type HealthCheck struct {
+	// Name identifies the check in the JSON report.
+	Name string
+
+	// Check is the function to run.
+	Check HealthCheckFunc
+
+	// Timeout overrides HealthConfig.Timeout for this check.
+	// Optional.
+	Timeout time.Duration
+
+	// Critical marks the check as required. A failing critical check makes
+	// the endpoint respond 503, a failing non-critical check only reports
+	// the endpoint as degraded.
+	Critical bool
+
+	// Liveness also runs the check on the liveness endpoint. Keep liveness
+	// checks cheap and independent of external dependencies.
+	Liveness bool
+}
+
+// HealthConfig defines the config for the health endpoints.
+type HealthConfig struct {
+	// LivenessPath is the liveness endpoint path, default is /healthz.
+	// Optional.
+	LivenessPath string
+
+	// ReadinessPath is the readiness endpoint path, default is /readyz.
+	// Optional.
+	ReadinessPath string
+
+	// Timeout is the default timeout of each check, default is 5s.
+	// Optional.
+	Timeout time.Duration
+
+	// CacheInterval caches check results for the given duration so that
+	// frequent probes do not hammer dependencies. Zero disables caching.
+	// Optional.
+	CacheInterval time.Duration
+}
+
+// HealthCheckResult is the JSON detail of a single check.
+type HealthCheckResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthReport is the JSON body written by the health endpoints.
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+type healthCache struct {
+	mu      sync.Mutex
+	report  HealthReport
+	code    int
+	expires time.Time
+}
+
+// Health holds the registered health checks of an Engine.
+type Health struct {
+	config HealthConfig
+
+	mu     sync.RWMutex
+	checks []HealthCheck
+
+	shuttingDown atomic.Bool
+
+	liveness  healthCache
+	readiness healthCache
+}
+
+// Health enables the liveness and readiness endpoints on the engine and
+// returns the health registry. The endpoints are registered on the first call
+// only; the config of later calls is ignored.
+func (engine *Engine) Health(config ...HealthConfig) *Health {
+	engine.healthOnce.Do(func() {
+		var conf HealthConfig
+		if len(config) > 0 {
+			conf = config[0]
+		}
+		if conf.LivenessPath == "" {
+			conf.LivenessPath = DefaultLivenessPath
+		}
+		if conf.ReadinessPath == "" {
+			conf.ReadinessPath = DefaultReadinessPath
+		}
+		if conf.Timeout <= 0 {
+			conf.Timeout = DefaultHealthCheckTimeout
+		}
+
+		h := &Health{config: conf}
+		engine.health = h
+
+		engine.GET(conf.LivenessPath, gin.HandlerFunc(func(c *gin.Context) {
+			skipLogging(c)
+			report, code := h.Liveness(c.Request.Context())
+			c.JSON(code, report)
+		}))
+		engine.GET(conf.ReadinessPath, gin.HandlerFunc(func(c *gin.Context) {
+			skipLogging(c)
+			report, code := h.Readiness(c.Request.Context())
+			c.JSON(code, report)
+		}))
+	})
+	return engine.health
+}
+
+// AddCheck registers a health check.
+func (h *Health) AddCheck(check HealthCheck) *Health {
+	if check.Name == "" || check.Check == nil {
+		panic("fox: health check requires a name and a check function")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check)
+	return h
+}
+
+// Shutdown flips readiness to failing. Call it before shutting the server
+// down so load balancers stop routing new requests to the instance.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// IsShuttingDown reports whether Shutdown has been called.
+func (h *Health) IsShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
+
+// RegisterOnShutdown arranges for readiness to fail as soon as
+// srv.Shutdown is called.
+func (h *Health) RegisterOnShutdown(srv *http.Server) {
+	srv.RegisterOnShutdown(h.Shutdown)
+}
+
+// Liveness runs the liveness checks and returns the report with its HTTP
+// status code.
+func (h *Health) Liveness(ctx context.Context) (HealthReport, int) {
+	return h.cached(&h.liveness, func() (HealthReport, int) {
+		return h.run(ctx, true)
+	})
+}
+
+// Readiness runs all checks and returns the report with its HTTP status code.
+// Readiness always fails once Shutdown has been called.
+func (h *Health) Readiness(ctx context.Context) (HealthReport, int) {
+	if h.IsShuttingDown() {
+		return HealthReport{Status: HealthStatusShuttingDown}, http.StatusServiceUnavailable
+	}
+	return h.cached(&h.readiness, func() (HealthReport, int) {
+		return h.run(ctx, false)
+	})
+}
+
+func (h *Health) cached(cache *healthCache, run func() (HealthReport, int)) (HealthReport, int) {
+	if h.config.CacheInterval <= 0 {
+		return run()
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if now := time.Now(); now.Before(cache.expires) {
+		return cache.report, cache.code
+	}
+	cache.report, cache.code = run()
+	cache.expires = time.Now().Add(h.config.CacheInterval)
+	return cache.report, cache.code
+}
+
+func (h *Health) run(ctx context.Context, livenessOnly bool) (HealthReport, int) {
+	h.mu.RLock()
+	checks := make([]HealthCheck, 0, len(h.checks))
+	for _, check := range h.checks {
+		if !livenessOnly || check.Liveness {
+			checks = append(checks, check)
+		}
+	}
+	h.mu.RUnlock()
+
+	report := HealthReport{Status: HealthStatusOK}
+	if len(checks) == 0 {
+		return report, http.StatusOK
+	}
+
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	report.Checks = make(map[string]HealthCheckResult, len(checks))
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == HealthStatusOK {
+			continue
+		}
+		if check.Critical {
+			report.Status = HealthStatusFail
+			code = http.StatusServiceUnavailable
+		} else if report.Status == HealthStatusOK {
+			report.Status = HealthStatusDegraded
+		}
+	}
+	return report, code
+}
+
+func (h *Health) runCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = h.config.Timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		start = time.Now()
+		done  = make(chan error, 1)
+		err   error
+	)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- &healthPanicError{value: r}
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := HealthCheckResult{
+		Status:   HealthStatusOK,
+		Critical: check.Critical,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = HealthStatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+type healthPanicError struct {
+	value any
+}
+
+func (e *healthPanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
@@ -0,0 +1,206 @@
+package fox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveHealth(t *testing.T, engine *Engine, path string) (int, HealthReport) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	engine.ServeHTTP(w, req)
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestHealth_DefaultEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	engine.Health()
+
+	code, report := serveHealth(t, engine, DefaultLivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusOK, report.Status)
+
+	code, report = serveHealth(t, engine, DefaultReadinessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusOK, report.Status)
+}
+
+func TestHealth_CustomPathsAndSameInstance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	h := engine.Health(HealthConfig{LivenessPath: "/live", ReadinessPath: "/ready"})
+	assert.Same(t, h, engine.Health())
+
+	code, _ := serveHealth(t, engine, "/live")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = serveHealth(t, engine, "/ready")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestHealth_ReadinessCriticality(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	h := engine.Health()
+	h.AddCheck(HealthCheck{
+		Name:  "cache",
+		Check: func(context.Context) error { return errors.New("cache down") },
+	})
+
+	code, report := serveHealth(t, engine, DefaultReadinessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusDegraded, report.Status)
+	assert.Equal(t, HealthStatusFail, report.Checks["cache"].Status)
+	assert.Equal(t, "cache down", report.Checks["cache"].Error)
+
+	h.AddCheck(HealthCheck{
+		Name:     "db",
+		Critical: true,
+		Check:    func(context.Context) error { return errors.New("db down") },
+	})
+
+	code, report = serveHealth(t, engine, DefaultReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.True(t, report.Checks["db"].Critical)
+
+	// Readiness-only checks do not affect liveness.
+	code, report = serveHealth(t, engine, DefaultLivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, report.Checks)
+}
+
+func TestHealth_LivenessChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	engine.Health().AddCheck(HealthCheck{
+		Name:     "goroutines",
+		Critical: true,
+		Liveness: true,
+		Check:    func(context.Context) error { return errors.New("deadlocked") },
+	})
+
+	code, report := serveHealth(t, engine, DefaultLivenessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Contains(t, report.Checks, "goroutines")
+}
+
+func TestHealth_CheckTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	engine.Health().AddCheck(HealthCheck{
+		Name:     "slow",
+		Critical: true,
+		Timeout:  10 * time.Millisecond,
+		Check: func(context.Context) error {
+			time.Sleep(200 * time.Millisecond)
+			return nil
+		},
+	})
+
+	start := time.Now()
+	code, report := serveHealth(t, engine, DefaultReadinessPath)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestHealth_CheckPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	engine.Health().AddCheck(HealthCheck{
+		Name:     "panics",
+		Critical: true,
+		Check:    func(context.Context) error { panic("boom") },
+	})
+
+	code, report := serveHealth(t, engine, DefaultReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "panic: boom", report.Checks["panics"].Error)
+}
+
+func TestHealth_CacheInterval(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	engine := New()
+	engine.Health(HealthConfig{CacheInterval: time.Hour}).AddCheck(HealthCheck{
+		Name: "counted",
+		Check: func(context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		code, _ := serveHealth(t, engine, DefaultReadinessPath)
+		assert.Equal(t, http.StatusOK, code)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHealth_Shutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := New()
+	h := engine.Health()
+
+	srv := &http.Server{Handler: engine}
+	h.RegisterOnShutdown(srv)
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	assert.Eventually(t, h.IsShuttingDown, time.Second, time.Millisecond)
+
+	code, report := serveHealth(t, engine, DefaultReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusShuttingDown, report.Status)
+
+	// Liveness keeps passing so the process is not restarted mid-drain.
+	code, _ = serveHealth(t, engine, DefaultLivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestHealth_AddCheckInvalid(t *testing.T) {
+	engine := New()
+	assert.Panics(t, func() {
+		engine.Health().AddCheck(HealthCheck{Name: "missing"})
+	})
+}
+
+func TestHealth_SkippedByLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var skipped bool
+	engine := New()
+	engine.Use(gin.HandlerFunc(func(c *gin.Context) {
+		c.Next()
+		skipped = c.GetBool(loggerSkipContextKey)
+	}))
+	engine.Health()
+
+	code, _ := serveHealth(t, engine, DefaultLivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, skipped)
+}
@@ -53,3 +53,31 @@ var ErrRequestEntityTooLarge = &Error{
 	Err:      errors.New("request entity too large"),
 	Code:     "REQUEST_ENTITY_TOO_LARGE",
 }
+
+// ErrServiceUnavailable service unavailable
+var ErrServiceUnavailable = &Error{
+	HTTPCode: http.StatusServiceUnavailable,
+	Err:      errors.New("service unavailable"),
+	Code:     "SERVICE_UNAVAILABLE",
+}
+
+// ErrGatewayTimeout gateway timeout
+var ErrGatewayTimeout = &Error{
+	HTTPCode: http.StatusGatewayTimeout,
+	Err:      errors.New("gateway timeout"),
+	Code:     "GATEWAY_TIMEOUT",
+}
+
+// ErrTooManyRequests too many requests
+var ErrTooManyRequests = &Error{
+	HTTPCode: http.StatusTooManyRequests,
+	Err:      errors.New("too many requests"),
+	Code:     "TOO_MANY_REQUESTS",
+}
+
+// ErrUnsupportedMediaType unsupported media type
+var ErrUnsupportedMediaType = &Error{
+	HTTPCode: http.StatusUnsupportedMediaType,
+	Err:      errors.New("unsupported media type"),
+	Code:     "UNSUPPORTED_MEDIA_TYPE",
+}
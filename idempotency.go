@@ -0,0 +1,277 @@
+package fox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// DefaultIdempotencyHeader is the request header carrying the idempotency key.
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the length of idempotency keys.
+const maxIdempotencyKeyLength = 255
+
+var (
+	// ErrIdempotencyKeyInFlight is returned while the first request with the
+	// same idempotency key is still being processed.
+	ErrIdempotencyKeyInFlight = &httperrors.Error{
+		HTTPCode: http.StatusConflict,
+		Err:      errors.New("a request with the same idempotency key is in progress"),
+		Code:     "IDEMPOTENCY_KEY_IN_FLIGHT",
+	}
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is reused
+	// with a different request.
+	ErrIdempotencyKeyReused = &httperrors.Error{
+		HTTPCode: http.StatusUnprocessableEntity,
+		Err:      errors.New("idempotency key was used with a different request"),
+		Code:     "IDEMPOTENCY_KEY_REUSED",
+	}
+
+	// ErrIdempotencyKeyInvalid is returned for idempotency keys longer than
+	// 255 characters.
+	ErrIdempotencyKeyInvalid = &httperrors.Error{
+		HTTPCode: http.StatusBadRequest,
+		Err:      errors.New("invalid idempotency key"),
+		Code:     "IDEMPOTENCY_KEY_INVALID",
+	}
+)
+
+// IdempotencyConfig defines the config for Idempotency middleware.
+type IdempotencyConfig struct {
+	// Header is the request header carrying the key, default is
+	// "Idempotency-Key".
+	// Optional.
+	Header string
+
+	// Methods the key is honored for, default is POST and PATCH.
+	// Optional.
+	Methods []string
+
+	// Store keeps keys and responses, default is a new
+	// MemoryIdempotencyStore.
+	// Optional.
+	Store IdempotencyStore
+
+	// TTL is how long a response is replayed, default is 24h.
+	// Optional.
+	TTL time.Duration
+
+	// LockTTL bounds how long a request may hold its key in flight, so that
+	// a crashed instance does not block retries forever, default is 1m.
+	// Optional.
+	LockTTL time.Duration
+
+	// Principal returns the authenticated principal of a request, so that
+	// the same key sent by different users does not collide.
+	// Optional.
+	Principal func(c *gin.Context) string
+}
+
+// Idempotency returns a middleware that makes retries of unsafe requests
+// safe. The first POST or PATCH request carrying an Idempotency-Key header is
+// processed normally and its response (status, headers and body) stored
+// under the key, the route template and the principal; retries with the same
+// key receive the stored response with an Idempotent-Replayed header instead
+// of running the handler again.
+//
+// A retry arriving while the first request is still in flight is answered
+// with 409 ErrIdempotencyKeyInFlight, and reusing a key for a different
+// request, fingerprinted from the request URI and Context.RequestBody, with
+// 422 ErrIdempotencyKeyReused. Responses with a 5xx status, streamed or
+// hijacked responses and panics release the key so the request can be
+// retried. Requests without the header are not affected.
+//
+//	payments := router.Group("/payments", fox.Idempotency(fox.IdempotencyConfig{
+//		Principal: func(c *gin.Context) string { return c.GetString("user") },
+//	}))
+func Idempotency(config ...IdempotencyConfig) *Middleware {
+	var conf IdempotencyConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Header == "" {
+		conf.Header = DefaultIdempotencyHeader
+	}
+	if len(conf.Methods) == 0 {
+		conf.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if conf.Store == nil {
+		conf.Store = NewMemoryIdempotencyStore()
+	}
+	if conf.TTL <= 0 {
+		conf.TTL = 24 * time.Hour
+	}
+	if conf.LockTTL <= 0 {
+		conf.LockTTL = time.Minute
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			if !slices.Contains(conf.Methods, c.Request.Method) {
+				return
+			}
+			idempotencyKey := c.GetHeader(conf.Header)
+			if idempotencyKey == "" {
+				return
+			}
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				AbortWithError(c, ErrIdempotencyKeyInvalid)
+				return
+			}
+
+			// The body is read within the limits of the engine and of
+			// BodyLimit, which handlers then rely on.
+			body, err := middlewareContext(c).RequestBody()
+			if err != nil {
+				var httpErr *httperrors.Error
+				if !errors.As(err, &httpErr) {
+					_ = c.Error(err)
+					httpErr = httperrors.ErrInvalidArguments
+				}
+				AbortWithError(c, httpErr)
+				return
+			}
+
+			var principal string
+			if conf.Principal != nil {
+				principal = conf.Principal(c)
+			}
+			key := strings.Join([]string{principal, c.Request.Method, c.FullPath(), idempotencyKey}, "\x00")
+			fingerprint := idempotencyFingerprint(c.Request.URL.RequestURI(), body)
+
+			ctx := c.Request.Context()
+			record, reserved, err := conf.Store.Reserve(ctx, key, fingerprint, conf.LockTTL)
+			if err != nil {
+				// Without the store the request cannot be deduplicated;
+				// process it rather than failing.
+				_ = c.Error(err)
+				return
+			}
+			if !reserved {
+				switch {
+				case record.Fingerprint != fingerprint:
+					AbortWithError(c, ErrIdempotencyKeyReused)
+				case record.InFlight:
+					AbortWithError(c, ErrIdempotencyKeyInFlight)
+				default:
+					replayIdempotentResponse(c, record)
+				}
+				return
+			}
+
+			before := c.Writer.Header().Clone()
+			w := &idempotencyWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+
+			saved := false
+			defer func() {
+				c.Writer = w.ResponseWriter
+				if !saved {
+					if err := conf.Store.Delete(ctx, key); err != nil {
+						_ = c.Error(err)
+					}
+				}
+			}()
+
+			c.Next()
+
+			status := w.Status()
+			if w.uncacheable || status >= http.StatusInternalServerError {
+				return
+			}
+			err = conf.Store.Save(ctx, key, &IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Status:      status,
+				Header:      handlerHeader(before, w.Header()),
+				Body:        w.body.Bytes(),
+			}, conf.TTL)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			saved = true
+		},
+		Meta: RouteMeta{"idempotency": map[string]any{
+			"header": conf.Header,
+			"ttl":    conf.TTL.String(),
+		}},
+	}
+}
+
+func idempotencyFingerprint(uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(uri))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// handlerHeader returns the headers of after that were not already set in
+// before, so that headers of outer middleware such as request IDs are not
+// replayed.
+func handlerHeader(before, after http.Header) http.Header {
+	header := make(http.Header, len(after))
+	for key, values := range after {
+		if !slices.Equal(before[key], values) {
+			header[key] = slices.Clone(values)
+		}
+	}
+	return header
+}
+
+func replayIdempotentResponse(c *gin.Context, record *IdempotencyRecord) {
+	header := c.Writer.Header()
+	maps.Copy(header, record.Header)
+	header.Set("Idempotent-Replayed", "true")
+	c.Writer.WriteHeader(record.Status)
+	if len(record.Body) == 0 {
+		c.Writer.WriteHeaderNow()
+	} else {
+		_, _ = c.Writer.Write(record.Body)
+	}
+	c.Abort()
+}
+
+// idempotencyWriter captures the response body for replay.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+
+	body        bytes.Buffer
+	uncacheable bool
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.body.WriteString(s[:n])
+	return n, err
+}
+
+func (w *idempotencyWriter) Flush() {
+	w.uncacheable = true
+	w.ResponseWriter.Flush()
+}
+
+func (w *idempotencyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.uncacheable = true
+	return w.ResponseWriter.Hijack()
+}
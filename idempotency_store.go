@@ -0,0 +1,117 @@
+package fox
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord is the state of an idempotency key: either a request in
+// flight or the response it produced.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+
+	// InFlight is true until the first request completes.
+	InFlight bool
+
+	// Status, Header and Body are the stored response.
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore keeps the state of idempotency keys. Implement it to share
+// keys between instances, for example in Redis; Reserve must be atomic.
+type IdempotencyStore interface {
+	// Reserve records an in-flight request for key unless the key is already
+	// known. It returns reserved true when the key was free, otherwise the
+	// existing record.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (record *IdempotencyRecord, reserved bool, err error)
+
+	// Save stores the completed response of key for ttl.
+	Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+
+	// Delete forgets key, letting the request be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+type idempotencyEntry struct {
+	record  *IdempotencyRecord
+	expires time.Time
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. Expired keys are
+// dropped lazily.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]idempotencyEntry
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+var _ IdempotencyStore = (*MemoryIdempotencyStore)(nil)
+
+// NewMemoryIdempotencyStore returns an in-memory IdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// Len returns the number of known keys, including expired keys not yet
+// dropped.
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		return entry.record, false, nil
+	}
+
+	record := &IdempotencyRecord{Fingerprint: fingerprint, InFlight: true}
+	s.entries[key] = idempotencyEntry{record: record, expires: now.Add(ttl)}
+	return record, true, nil
+}
+
+// Save implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Save(_ context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = idempotencyEntry{record: record, expires: s.now().Add(ttl)}
+	return nil
+}
+
+// Delete implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired keys at most once a minute.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
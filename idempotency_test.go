@@ -0,0 +1,216 @@
+package fox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type idempotencyPayment struct {
+	Amount int `json:"amount"`
+}
+
+func idempotencyRouter(calls *atomic.Int32, config ...IdempotencyConfig) *Engine {
+	router := New()
+	router.POST("/payments", Idempotency(config...), func(c *Context, p *idempotencyPayment) (map[string]any, error) {
+		n := calls.Add(1)
+		c.Header("X-Payment", "p"+string(rune('0'+n)))
+		return map[string]any{"amount": p.Amount, "call": n}, nil
+	})
+	return router
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls)
+
+	first := serve(router, http.MethodPost, "/payments", `{"amount":10}`, DefaultIdempotencyHeader, "k1")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"amount":10,"call":1}`, first.Body.String())
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	retry := serve(router, http.MethodPost, "/payments", `{"amount":10}`, DefaultIdempotencyHeader, "k1")
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "p1", retry.Header().Get("X-Payment"))
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Another key and requests without a key run the handler.
+	assert.JSONEq(t, `{"amount":10,"call":2}`, serve(router, http.MethodPost, "/payments", `{"amount":10}`, DefaultIdempotencyHeader, "k2").Body.String())
+	assert.JSONEq(t, `{"amount":10,"call":3}`, serve(router, http.MethodPost, "/payments", `{"amount":10}`).Body.String())
+}
+
+func TestIdempotency_DifferentBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls)
+
+	serve(router, http.MethodPost, "/payments", `{"amount":10}`, DefaultIdempotencyHeader, "k1")
+	w := serve(router, http.MethodPost, "/payments", `{"amount":99}`, DefaultIdempotencyHeader, "k1")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", body["code"])
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router := New()
+	router.POST("/payments", Idempotency(), func() string {
+		close(started)
+		<-release
+		return "done"
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, "k1")
+	}()
+	<-started
+
+	w := serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, "k1")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "IDEMPOTENCY_KEY_IN_FLIGHT")
+
+	close(release)
+	assert.Equal(t, "done", (<-done).Body.String())
+	assert.Equal(t, "done", serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, "k1").Body.String())
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	router := New()
+	router.POST("/payments", Idempotency(), func(c *Context) {
+		if calls.Add(1) == 1 {
+			c.String(http.StatusBadGateway, "upstream failed")
+			return
+		}
+		c.String(http.StatusOK, "paid")
+	})
+
+	assert.Equal(t, http.StatusBadGateway, serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, "k1").Code)
+	assert.Equal(t, "paid", serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, "k1").Body.String())
+	assert.Equal(t, "paid", serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, "k1").Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotency_Principal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls, IdempotencyConfig{
+		Principal: func(c *gin.Context) string { return c.GetHeader("X-User") },
+	})
+
+	serve(router, http.MethodPost, "/payments", `{"amount":1}`, DefaultIdempotencyHeader, "k1", "X-User", "alice")
+	w := serve(router, http.MethodPost, "/payments", `{"amount":1}`, DefaultIdempotencyHeader, "k1", "X-User", "bob")
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotency_IgnoredMethodsAndLongKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	router := New()
+	mw := Idempotency()
+	router.PUT("/payments", mw, func() int32 { return calls.Add(1) })
+	router.POST("/payments", mw, func() int32 { return calls.Add(1) })
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/payments", nil)
+		req.Header.Set(DefaultIdempotencyHeader, "k1")
+		router.ServeHTTP(w, req)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	w := serve(router, http.MethodPost, "/payments", `{}`, DefaultIdempotencyHeader, strings.Repeat("k", 256))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "IDEMPOTENCY_KEY_INVALID")
+}
+
+func TestIdempotency_BodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls)
+	router.MaxBodySize = 10
+	limited := New()
+	limited.POST("/payments", BodyLimit(BodyLimitConfig{MaxSize: 10}), Idempotency(), func() string { return "paid" })
+
+	body := `{"amount":` + strings.Repeat("1", 1000) + `}`
+	for name, router := range map[string]*Engine{"engine": router, "middleware": limited} {
+		t.Run(name, func(t *testing.T) {
+			// Hide the length so that the limit is enforced while reading.
+			req := httptest.NewRequest(http.MethodPost, "/payments", io.MultiReader(strings.NewReader(body)))
+			req.ContentLength = -1
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(DefaultIdempotencyHeader, "k1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		})
+	}
+	assert.Zero(t, calls.Load())
+}
+
+func TestIdempotency_Meta(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotencyRouter(&calls, IdempotencyConfig{TTL: time.Hour})
+
+	routes := router.HandlerRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, map[string]any{"header": "Idempotency-Key", "ttl": "1h0m0s"}, routes[0].Meta["idempotency"])
+}
+
+func TestMemoryIdempotencyStore_Expiry(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryIdempotencyStore()
+	store.now = clock.Now
+	ctx := context.Background()
+
+	record, reserved, err := store.Reserve(ctx, "k", "f", time.Second)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.True(t, record.InFlight)
+
+	record, reserved, _ = store.Reserve(ctx, "k", "other", time.Second)
+	assert.False(t, reserved)
+	assert.Equal(t, "f", record.Fingerprint)
+
+	// An in-flight key whose lock expired can be reserved again.
+	clock.Advance(2 * time.Second)
+	_, reserved, _ = store.Reserve(ctx, "k", "f", time.Second)
+	assert.True(t, reserved)
+
+	require.NoError(t, store.Save(ctx, "k", &IdempotencyRecord{Fingerprint: "f", Status: http.StatusOK}, time.Hour))
+	record, reserved, _ = store.Reserve(ctx, "k", "f", time.Second)
+	assert.False(t, reserved)
+	assert.False(t, record.InFlight)
+
+	require.NoError(t, store.Delete(ctx, "k"))
+	assert.Equal(t, 0, store.Len())
+}
@@ -7,11 +7,32 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/fox-gonic/fox/logger"
+	"github.com/fox-gonic/fox/tracing"
 )
 
 // LoggerContextKey logger save in gin context
 var LoggerContextKey = "_fox-goinc/fox/logger/context/key"
 
+// loggerSkipContextKey marks a request as not to be logged by Logger.
+const loggerSkipContextKey = "_fox-gonic/fox/logger/skip"
+
+// skipLogging tells the Logger middleware not to log the current request.
+// Built-in endpoints such as health probes use it to keep logs quiet.
+func skipLogging(c *gin.Context) {
+	c.Set(loggerSkipContextKey, true)
+}
+
+// requestLogger returns the logger set by the Logger middleware, or a new
+// logger for the request trace ID.
+func requestLogger(c *gin.Context) logger.Logger {
+	if v, exists := c.Get(LoggerContextKey); exists {
+		if log, ok := v.(logger.Logger); ok {
+			return log
+		}
+	}
+	return logger.New(c.Writer.Header().Get(logger.TraceID))
+}
+
 // LoggerConfig defines the config for Logger middleware.
 type LoggerConfig struct {
 	// SkipPaths is an url path array which logs are not written.
@@ -44,13 +65,21 @@ func Logger(config ...LoggerConfig) gin.HandlerFunc {
 		)
 
 		if len(xRequestID) == 0 {
-			xRequestID = logger.DefaultGenRequestID()
+			// Reuse the W3C trace ID so logs correlate with distributed traces.
+			if sc, ok := tracing.Extract(c.Request.Header); ok {
+				xRequestID = sc.TraceID.String()
+			} else {
+				xRequestID = logger.DefaultGenRequestID()
+			}
 			if c.Request.Header != nil {
 				c.Request.Header.Set(logger.TraceID, xRequestID)
 			}
 		}
 
-		log := logger.New(xRequestID)
+		var log logger.Logger = logger.New(xRequestID)
+		if span := tracing.SpanFromContext(c.Request.Context()); span != nil {
+			log = log.WithFields(traceLogFields(span.SpanContext()))
+		}
 		c.Request = c.Request.WithContext(log.WithContext(c.Request.Context()))
 		c.Set(LoggerContextKey, log)
 
@@ -58,7 +87,7 @@ func Logger(config ...LoggerConfig) gin.HandlerFunc {
 		c.Next()
 
 		// Log only when path is not being skipped
-		if _, ok := skip[path]; !ok {
+		if _, ok := skip[path]; !ok && !c.GetBool(loggerSkipContextKey) {
 			if raw := c.Request.URL.RawQuery; raw != "" {
 				path = path + "?" + raw
 			}
@@ -78,6 +107,10 @@ func Logger(config ...LoggerConfig) gin.HandlerFunc {
 				"latency":   time.Since(start).String(),
 			}
 
+			if timing := serverTimingLogFields(c); timing != nil {
+				fields["server_timing"] = timing
+			}
+
 			errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
 
 			log.WithFields(fields).Info(errorMessage)
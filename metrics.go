@@ -0,0 +1,385 @@
+package fox
+
+import (
+	"bufio"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMetricsPath is the conventional path of the metrics endpoint.
+const DefaultMetricsPath = "/metrics"
+
+// metricsUnmatchedRoute is the route label of requests that matched no route.
+const metricsUnmatchedRoute = "unmatched"
+
+// metricsOtherMethod is the method label of requests with a nonstandard
+// method, which would otherwise let clients create any number of series.
+const metricsOtherMethod = "OTHER"
+
+// metricsContentType is the Prometheus text exposition format content type.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultMetricsDurationBuckets are the default latency histogram buckets in
+// seconds.
+var DefaultMetricsDurationBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// DefaultMetricsSizeBuckets are the default response size histogram buckets
+// in bytes.
+var DefaultMetricsSizeBuckets = []float64{
+	100, 1000, 10_000, 100_000, 1_000_000, 10_000_000,
+}
+
+// MetricsConfig defines the config for Metrics.
+type MetricsConfig struct {
+	// Namespace prefixes every metric name, default is "fox".
+	// Optional.
+	Namespace string
+
+	// DurationBuckets are the latency histogram buckets in seconds.
+	// Optional.
+	DurationBuckets []float64
+
+	// SizeBuckets are the response size histogram buckets in bytes.
+	// Optional.
+	SizeBuckets []float64
+
+	// SkipPaths is an url path array which are not measured.
+	// Optional.
+	SkipPaths []string
+}
+
+// metricsKey identifies one labeled series. Route is the route template from
+// gin.Context.FullPath so that path parameters do not explode cardinality.
+type metricsKey struct {
+	Domain string
+	Method string
+	Route  string
+	Status string
+}
+
+type metricsInFlightKey struct {
+	Domain string
+	Method string
+	Route  string
+}
+
+type metricsHistogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *metricsHistogram) observe(buckets []float64, value float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	for i, bound := range buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+type metricsSeries struct {
+	requests uint64
+	duration metricsHistogram
+	size     metricsHistogram
+}
+
+// Metrics collects HTTP request metrics and writes them in the Prometheus text
+// exposition format, without depending on a Prometheus client library.
+//
+// Series are labeled by method, route template, status class and, when the
+// request was dispatched by a DomainEngine, by domain. Nonstandard methods
+// share the method label "OTHER".
+type Metrics struct {
+	namespace       string
+	durationBuckets []float64
+	sizeBuckets     []float64
+	skip            map[string]struct{}
+
+	mu       sync.Mutex
+	series   map[metricsKey]*metricsSeries
+	inFlight map[metricsInFlightKey]int64
+}
+
+// NewMetrics returns a metrics collector. Attach Middleware to the engines to
+// measure and serve Handler on the metrics path, usually DefaultMetricsPath.
+func NewMetrics(config ...MetricsConfig) *Metrics {
+	var conf MetricsConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+
+	m := &Metrics{
+		namespace:       conf.Namespace,
+		durationBuckets: conf.DurationBuckets,
+		sizeBuckets:     conf.SizeBuckets,
+		series:          make(map[metricsKey]*metricsSeries),
+		inFlight:        make(map[metricsInFlightKey]int64),
+	}
+	if m.namespace == "" {
+		m.namespace = "fox"
+	}
+	if len(m.durationBuckets) == 0 {
+		m.durationBuckets = DefaultMetricsDurationBuckets
+	}
+	if len(m.sizeBuckets) == 0 {
+		m.sizeBuckets = DefaultMetricsSizeBuckets
+	}
+	m.durationBuckets = sortedBuckets(m.durationBuckets)
+	m.sizeBuckets = sortedBuckets(m.sizeBuckets)
+
+	if length := len(conf.SkipPaths); length > 0 {
+		m.skip = make(map[string]struct{}, length)
+		for _, path := range conf.SkipPaths {
+			m.skip[path] = struct{}{}
+		}
+	}
+
+	return m
+}
+
+// metricsMethod returns the method label of method.
+func metricsMethod(method string) string {
+	if slices.Contains(anyMethods, method) {
+		return method
+	}
+	return metricsOtherMethod
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	result := append([]float64(nil), buckets...)
+	sort.Float64s(result)
+	return result
+}
+
+// Middleware returns the middleware measuring every request.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := m.skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		var (
+			start    = time.Now()
+			inFlight = metricsInFlightKey{
+				Domain: DomainFromContext(c.Request.Context()),
+				Method: metricsMethod(c.Request.Method),
+				Route:  c.FullPath(),
+			}
+		)
+		if inFlight.Route == "" {
+			inFlight.Route = metricsUnmatchedRoute
+		}
+
+		m.mu.Lock()
+		m.inFlight[inFlight]++
+		m.mu.Unlock()
+
+		defer func() {
+			var (
+				duration = time.Since(start).Seconds()
+				size     = c.Writer.Size()
+				key      = metricsKey{
+					Domain: inFlight.Domain,
+					Method: inFlight.Method,
+					Route:  inFlight.Route,
+					Status: metricsStatusClass(c.Writer.Status()),
+				}
+			)
+			if size < 0 {
+				size = 0
+			}
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			if m.inFlight[inFlight]--; m.inFlight[inFlight] == 0 {
+				delete(m.inFlight, inFlight)
+			}
+			series, ok := m.series[key]
+			if !ok {
+				series = &metricsSeries{}
+				m.series[key] = series
+			}
+			series.requests++
+			series.duration.observe(m.durationBuckets, duration)
+			series.size.observe(m.sizeBuckets, float64(size))
+		}()
+
+		c.Next()
+	}
+}
+
+// Handler returns the handler writing all metrics in the Prometheus text
+// exposition format.
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", metricsContentType)
+		w := bufio.NewWriter(c.Writer)
+		m.write(w)
+		_ = w.Flush()
+	}
+}
+
+func (m *Metrics) write(w *bufio.Writer) {
+	m.mu.Lock()
+	keys := make([]metricsKey, 0, len(m.series))
+	series := make(map[metricsKey]metricsSeries, len(m.series))
+	for key, s := range m.series {
+		keys = append(keys, key)
+		series[key] = metricsSeries{
+			requests: s.requests,
+			duration: metricsHistogram{counts: append([]uint64(nil), s.duration.counts...), sum: s.duration.sum, count: s.duration.count},
+			size:     metricsHistogram{counts: append([]uint64(nil), s.size.counts...), sum: s.size.sum, count: s.size.count},
+		}
+	}
+	inFlightKeys := make([]metricsInFlightKey, 0, len(m.inFlight))
+	inFlight := make(map[metricsInFlightKey]int64, len(m.inFlight))
+	for key, value := range m.inFlight {
+		inFlightKeys = append(inFlightKeys, key)
+		inFlight[key] = value
+	}
+	m.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+	sort.Slice(inFlightKeys, func(i, j int) bool {
+		a, b := inFlightKeys[i], inFlightKeys[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.Method < b.Method
+	})
+
+	name := m.namespace + "_http_requests_total"
+	writeMetricsHeader(w, name, "Total number of HTTP requests.", "counter")
+	for _, key := range keys {
+		writeMetricsSample(w, name, key.labels(), "", "", float64(series[key].requests))
+	}
+
+	name = m.namespace + "_http_request_duration_seconds"
+	writeMetricsHeader(w, name, "HTTP request latency in seconds.", "histogram")
+	for _, key := range keys {
+		s := series[key]
+		writeMetricsHistogram(w, name, key.labels(), m.durationBuckets, &s.duration)
+	}
+
+	name = m.namespace + "_http_response_size_bytes"
+	writeMetricsHeader(w, name, "HTTP response size in bytes.", "histogram")
+	for _, key := range keys {
+		s := series[key]
+		writeMetricsHistogram(w, name, key.labels(), m.sizeBuckets, &s.size)
+	}
+
+	name = m.namespace + "_http_requests_in_flight"
+	writeMetricsHeader(w, name, "Number of HTTP requests currently being served.", "gauge")
+	for _, key := range inFlightKeys {
+		labels := metricsKey{Domain: key.Domain, Method: key.Method, Route: key.Route}.labels()
+		writeMetricsSample(w, name, labels, "", "", float64(inFlight[key]))
+	}
+}
+
+// labels returns the label pairs of the series; the domain label is only
+// present for requests dispatched by a DomainEngine and status only for
+// completed requests.
+func (key metricsKey) labels() [][2]string {
+	labels := make([][2]string, 0, 4)
+	if key.Domain != "" {
+		labels = append(labels, [2]string{"domain", key.Domain})
+	}
+	labels = append(labels, [2]string{"method", key.Method}, [2]string{"route", key.Route})
+	if key.Status != "" {
+		labels = append(labels, [2]string{"status", key.Status})
+	}
+	return labels
+}
+
+func metricsStatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+func writeMetricsHeader(w *bufio.Writer, name, help, typ string) {
+	_, _ = w.WriteString("# HELP " + name + " " + help + "\n")
+	_, _ = w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func writeMetricsHistogram(w *bufio.Writer, name string, labels [][2]string, buckets []float64, h *metricsHistogram) {
+	for i, bound := range buckets {
+		var count uint64
+		if i < len(h.counts) {
+			count = h.counts[i]
+		}
+		writeMetricsSample(w, name+"_bucket", labels, "le", formatMetricsFloat(bound), float64(count))
+	}
+	writeMetricsSample(w, name+"_bucket", labels, "le", "+Inf", float64(h.count))
+	writeMetricsSample(w, name+"_sum", labels, "", "", h.sum)
+	writeMetricsSample(w, name+"_count", labels, "", "", float64(h.count))
+}
+
+func writeMetricsSample(w *bufio.Writer, name string, labels [][2]string, extraName, extraValue string, value float64) {
+	_, _ = w.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		_ = w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				_ = w.WriteByte(',')
+			}
+			writeMetricsLabel(w, label[0], label[1])
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				_ = w.WriteByte(',')
+			}
+			writeMetricsLabel(w, extraName, extraValue)
+		}
+		_ = w.WriteByte('}')
+	}
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(formatMetricsFloat(value))
+	_ = w.WriteByte('\n')
+}
+
+var metricsLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeMetricsLabel(w *bufio.Writer, name, value string) {
+	_, _ = w.WriteString(name)
+	_, _ = w.WriteString(`="`)
+	_, _ = metricsLabelReplacer.WriteString(w, value)
+	_ = w.WriteByte('"')
+}
+
+func formatMetricsFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,160 @@
+package fox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+func scrapeMetrics(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, DefaultMetricsPath, nil)
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, metricsContentType, w.Header().Get("Content-Type"))
+	return w.Body.String()
+}
+
+func metricsServer(m *Metrics) http.Handler {
+	router := gin.New()
+	router.GET(DefaultMetricsPath, m.Handler())
+	return router
+}
+
+func TestMetrics_RouteTemplateLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := NewMetrics()
+	router := New()
+	router.Use(m.Middleware())
+	router.GET(DefaultMetricsPath, m.Handler())
+	router.GET("/users/:id", func(c *Context) string {
+		return "user " + c.Param("id")
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/"+id, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	body := scrapeMetrics(t, router)
+
+	assert.Contains(t, body, "# TYPE fox_http_requests_total counter\n")
+	assert.Contains(t, body, `fox_http_requests_total{method="GET",route="/users/:id",status="2xx"} 3`)
+	assert.NotContains(t, body, `route="/users/1"`)
+	assert.Contains(t, body, `fox_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="+Inf"} 3`)
+	assert.Contains(t, body, `fox_http_request_duration_seconds_count{method="GET",route="/users/:id",status="2xx"} 3`)
+	assert.Contains(t, body, `fox_http_response_size_bytes_sum{method="GET",route="/users/:id",status="2xx"} 18`)
+	assert.Contains(t, body, `fox_http_response_size_bytes_bucket{method="GET",route="/users/:id",status="2xx",le="100"} 3`)
+	assert.Contains(t, body, "# TYPE fox_http_requests_in_flight gauge\n")
+	assert.Contains(t, body, `fox_http_requests_in_flight{method="GET",route="/metrics"} 1`)
+	assert.NotContains(t, body, `fox_http_requests_in_flight{method="GET",route="/users/:id"}`, "finished requests are dropped")
+}
+
+func TestMetrics_NonstandardMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := NewMetrics()
+	router := New()
+	router.Use(m.Middleware())
+	for _, method := range []string{"BREW", "PURGE", http.MethodDelete} {
+		router.Handle(method, "/users", func() string { return "ok" })
+	}
+
+	for _, method := range []string{"BREW", "PURGE", http.MethodDelete} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/users", nil)
+		router.ServeHTTP(w, req)
+	}
+
+	body := scrapeMetrics(t, metricsServer(m))
+	assert.Contains(t, body, `fox_http_requests_total{method="OTHER",route="/users",status="2xx"} 2`)
+	assert.Contains(t, body, `fox_http_requests_total{method="DELETE",route="/users",status="2xx"} 1`)
+	assert.NotContains(t, body, "BREW")
+}
+
+func TestMetrics_StatusClassAndUnmatched(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := NewMetrics(MetricsConfig{Namespace: "app", SkipPaths: []string{"/skip"}})
+	router := New()
+	router.Use(m.Middleware())
+	router.GET("/fail", func() (any, error) {
+		return nil, &metricsTestError{}
+	})
+	router.GET("/skip", func() string { return "skip" })
+	router.NotFound(func() (any, error) { return nil, httperrors.ErrNotFound })
+
+	for _, path := range []string{"/fail", "/missing", "/skip"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+	}
+
+	body := scrapeMetrics(t, metricsServer(m))
+
+	assert.Contains(t, body, `app_http_requests_total{method="GET",route="/fail",status="5xx"} 1`)
+	assert.Contains(t, body, `app_http_requests_total{method="GET",route="unmatched",status="4xx"} 1`)
+	assert.NotContains(t, body, `route="/skip"`)
+}
+
+type metricsTestError struct{}
+
+func (*metricsTestError) Error() string   { return "unavailable" }
+func (*metricsTestError) StatusCode() int { return http.StatusServiceUnavailable }
+
+func TestMetrics_DomainLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := NewMetrics()
+	de := NewDomainEngine(func() *Engine {
+		engine := New()
+		engine.Use(m.Middleware())
+		return engine
+	})
+	de.Domain("api.example.com", func(sub *Engine) {
+		sub.GET("/ping", func() string { return "pong" })
+	})
+	de.GET(DefaultMetricsPath, m.Handler())
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Host = "api.example.com:8080"
+	de.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := scrapeMetrics(t, de)
+	assert.Contains(t, body, `fox_http_requests_total{domain="api.example.com",method="GET",route="/ping",status="2xx"} 1`)
+}
+
+func TestMetrics_LabelEscaping(t *testing.T) {
+	m := NewMetrics()
+	m.series[metricsKey{Method: "GET", Route: "/a\"b\\c\n", Status: "2xx"}] = &metricsSeries{requests: 1}
+
+	body := scrapeMetrics(t, metricsServer(m))
+	assert.Contains(t, body, `fox_http_requests_total{method="GET",route="/a\"b\\c\n",status="2xx"} 1`)
+}
+
+func TestMetricsStatusClass(t *testing.T) {
+	assert.Equal(t, "2xx", metricsStatusClass(http.StatusOK))
+	assert.Equal(t, "3xx", metricsStatusClass(http.StatusFound))
+	assert.Equal(t, "4xx", metricsStatusClass(http.StatusNotFound))
+	assert.Equal(t, "5xx", metricsStatusClass(http.StatusInternalServerError))
+	assert.Equal(t, "unknown", metricsStatusClass(0))
+}
+
+func TestDomainFromContext(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, DomainFromContext(req.Context()))
+}
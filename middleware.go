@@ -0,0 +1,85 @@
+package fox
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteMeta describes how a route is configured, for example its timeout or
+// rate limit. It is recorded in RouteInfo and exported in the route manifest,
+// so values should be JSON serializable.
+type RouteMeta map[string]any
+
+// RouteMetaList is a route metadata value that accumulates instead of being
+// replaced when several middleware of a route set the same key, for example
+// a group rate limit and a stricter route rate limit.
+type RouteMetaList []any
+
+// Middleware is a gin middleware that also describes the routes it is
+// attached to. Pass it like any other handler to Use, Group or Handle; its
+// Meta is merged into the RouteInfo of every route it applies to.
+type Middleware struct {
+	// Handler is the middleware itself. Required.
+	Handler gin.HandlerFunc
+
+	// Meta is merged into the route metadata, later middleware winning on
+	// duplicate keys unless both values are a RouteMetaList.
+	// Optional.
+	Meta RouteMeta
+}
+
+// mergeRouteMeta returns base extended with the metadata of the Middleware
+// values in handlers. base is never modified.
+func mergeRouteMeta(base RouteMeta, handlers []HandlerFunc) RouteMeta {
+	var meta RouteMeta
+	for _, handler := range handlers {
+		m, ok := handler.(*Middleware)
+		if !ok || len(m.Meta) == 0 {
+			continue
+		}
+		if meta == nil {
+			meta = make(RouteMeta, len(base)+len(m.Meta))
+			maps.Copy(meta, base)
+		}
+		for key, value := range m.Meta {
+			list, ok := value.(RouteMetaList)
+			if prev, exists := meta[key].(RouteMetaList); ok && exists {
+				value = append(append(RouteMetaList{}, prev...), list...)
+			}
+			meta[key] = value
+		}
+	}
+	if meta == nil {
+		return base
+	}
+	return meta
+}
+
+// AbortWithError records err and aborts the request with it, rendered like
+// the errors of fox handlers: by the RenderErrorFunc of the engine, or as
+// JSON wrapped by its Envelope. It is meant for gin middleware, which run
+// outside of fox handlers.
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	defer c.Abort()
+
+	ctx := middlewareContext(c)
+	if ctx.engine != nil {
+		ctx.renderError(err)
+		return
+	}
+
+	// Outside of an Engine, such as in gin tests.
+	code := http.StatusInternalServerError
+	if e, ok := err.(StatusCoder); ok && e.StatusCode() != 0 {
+		code = e.StatusCode()
+	}
+	if e, ok := err.(json.Marshaler); ok {
+		c.JSON(code, e)
+	} else {
+		c.String(code, err.Error())
+	}
+}
@@ -0,0 +1,70 @@
+package fox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRouteMeta(t *testing.T) {
+	base := RouteMeta{"a": 1}
+
+	assert.Equal(t, base, mergeRouteMeta(base, nil))
+	assert.Nil(t, mergeRouteMeta(nil, []HandlerFunc{func() {}}))
+
+	merged := mergeRouteMeta(base, []HandlerFunc{
+		&Middleware{Handler: func(*gin.Context) {}, Meta: RouteMeta{"a": 2, "b": 1}},
+		func() {},
+		&Middleware{Handler: func(*gin.Context) {}, Meta: RouteMeta{"c": 1}},
+	})
+	assert.Equal(t, RouteMeta{"a": 2, "b": 1, "c": 1}, merged)
+	assert.Equal(t, RouteMeta{"a": 1}, base, "base must not be modified")
+
+	list := RouteMeta{"l": RouteMetaList{1}}
+	merged = mergeRouteMeta(list, []HandlerFunc{
+		&Middleware{Handler: func(*gin.Context) {}, Meta: RouteMeta{"l": RouteMetaList{2}}},
+	})
+	assert.Equal(t, RouteMeta{"l": RouteMetaList{1, 2}}, merged)
+	assert.Equal(t, RouteMeta{"l": RouteMetaList{1}}, list, "base list must not be modified")
+}
+
+func TestMiddleware_RunsAndRecordsMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tag := func(name string) *Middleware {
+		return &Middleware{
+			Handler: func(c *gin.Context) {
+				c.Header("X-"+name, "1")
+			},
+			Meta: RouteMeta{name: true},
+		}
+	}
+
+	router := New()
+	router.Use(tag("engine"))
+	group := router.Group("/g", tag("group"))
+	group.GET("/r", tag("route"), func() string { return "ok" })
+	router.GET("/other", func() string { return "ok" })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/g/r", nil))
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-engine"))
+	assert.Equal(t, "1", w.Header().Get("X-group"))
+	assert.Equal(t, "1", w.Header().Get("X-route"))
+
+	manifest := RouteManifestFromEngine(router)
+	require.Len(t, manifest.Routes, 2)
+	assert.Equal(t, RouteMeta{"engine": true, "group": true, "route": true}, manifest.Routes[0].Meta)
+	assert.Equal(t, RouteMeta{"engine": true}, manifest.Routes[1].Meta)
+}
+
+func TestIsValidHandlerFunc_Middleware(t *testing.T) {
+	assert.True(t, IsValidHandlerFunc(&Middleware{Handler: func(*gin.Context) {}}))
+	assert.False(t, IsValidHandlerFunc(&Middleware{}))
+	assert.False(t, IsValidHandlerFunc((*Middleware)(nil)))
+}
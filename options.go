@@ -0,0 +1,81 @@
+package fox
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleFallback runs first in the NoRoute and NoMethod chains. For paths
+// with registered routes, it answers preflight requests with the CORS policy
+// of the requested route, OPTIONS requests with the Allow header when
+// HandleOptions is set, and sets the Allow header of 405 responses. Other
+// unmatched requests are left alone without looking the routes up.
+func (engine *Engine) handleFallback(c *gin.Context) {
+	if isPreflight(c.Request) {
+		routes := engine.matchCORSRoutes(c.Request.URL.Path)
+		if policy := corsPolicyFor(routes, c.GetHeader("Access-Control-Request-Method")); policy != nil {
+			c.Writer.Header().Del("Allow")
+			policy.handlePreflight(c, routes)
+			return
+		}
+	}
+
+	options := c.Request.Method == http.MethodOptions && engine.HandleOptions
+	if !options && c.Writer.Status() != http.StatusMethodNotAllowed {
+		return
+	}
+
+	methods := engine.matchRouteMethods(c.Request.URL.Path)
+	if len(methods) == 0 {
+		return
+	}
+
+	if engine.HandleOptions && !slices.Contains(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	slices.Sort(methods)
+	c.Header("Allow", strings.Join(methods, ", "))
+	if options {
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// registerRouteMethod records the method of a route for the Allow header.
+func (engine *Engine) registerRouteMethod(method, path string) {
+	engine.routeMethodsMu.Lock()
+	defer engine.routeMethodsMu.Unlock()
+
+	if engine.routeMethods == nil {
+		engine.routeMethods = make(map[string][]string)
+	}
+	methods, exists := engine.routeMethods[path]
+	if !exists && strings.ContainsAny(path, ":*") {
+		engine.paramRoutes = append(engine.paramRoutes, path)
+	}
+	if !slices.Contains(methods, method) {
+		engine.routeMethods[path] = append(methods, method)
+	}
+}
+
+// matchRouteMethods returns the methods of the routes whose path template
+// matches the request path. Static paths are looked up directly.
+func (engine *Engine) matchRouteMethods(path string) []string {
+	engine.routeMethodsMu.RLock()
+	defer engine.routeMethodsMu.RUnlock()
+
+	methods := slices.Clone(engine.routeMethods[path])
+	for _, template := range engine.paramRoutes {
+		if template == path || !routePathMatches(template, path) {
+			continue
+		}
+		for _, method := range engine.routeMethods[template] {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods
+}
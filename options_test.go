@@ -0,0 +1,162 @@
+package fox
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_HandleOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/users/:id", func() string { return "user" })
+	router.PUT("/users/:id", func() string { return "updated" })
+	router.GET("/health", func() string { return "ok" })
+	router.OPTIONS("/health", func(c *Context) {
+		c.Header("Allow", "GET")
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("answers with registered methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users/1", nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET, OPTIONS, PUT", w.Header().Get("Allow"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("OPTIONS route overrides", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "GET", w.Header().Get("Allow"))
+	})
+
+	t.Run("unknown paths are not found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("Allow"))
+	})
+}
+
+func TestEngine_HandleOptionsDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.HandleOptions = false
+	router.GET("/users", func() string { return "users" })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Allow"))
+}
+
+func TestEngine_MethodNotAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("default response", func(t *testing.T) {
+		router := New()
+		router.GET("/users/:id", func() string { return "user" })
+		router.DELETE("/users/:id", func() string { return "deleted" })
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/1", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "DELETE, GET, OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("NoMethod handlers", func(t *testing.T) {
+		router := New()
+		router.NoMethod(func(c *Context) {
+			c.JSON(http.StatusMethodNotAllowed, gin.H{"allow": c.Writer.Header().Get("Allow")})
+		})
+		router.GET("/users", func() string { return "users" })
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/users", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.JSONEq(t, `{"allow":"GET, OPTIONS"}`, w.Body.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := New()
+		router.HandleMethodNotAllowed = false
+		router.GET("/users", func() string { return "users" })
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestEngine_AllowWithoutRouteRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.DisableRouteRegistry()
+	router.GET("/users/:id", func() string { return "user" })
+	router.GET("/users/new", func() string { return "form" })
+	router.POST("/users/new", func() string { return "created" })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users/new", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, OPTIONS, POST", w.Header().Get("Allow"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, OPTIONS", w.Header().Get("Allow"))
+}
+
+func TestEngine_HandleOptionsWithCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	api := router.Group("/api")
+	require.NoError(t, api.CORS(cors.Config{AllowOrigins: []string{"https://app.example.com"}}))
+	api.GET("/users", func() string { return "users" })
+	router.GET("/public", func() string { return "public" })
+
+	w := corsRequest(t, router, http.MethodOptions, "/api/users", "https://app.example.com", http.MethodGet)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Allow"))
+
+	// Preflight requests for routes without a policy get a plain answer.
+	w = corsRequest(t, router, http.MethodOptions, "/public", "https://app.example.com", http.MethodGet)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, OPTIONS", w.Header().Get("Allow"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func BenchmarkEngine_NotFound(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	for i := range 1000 {
+		router.GET(fmt.Sprintf("/resources/%d/:id", i), func() string { return "ok" })
+	}
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
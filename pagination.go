@@ -0,0 +1,307 @@
+package fox
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// Pagination defaults, see PaginationConfig.
+const (
+	DefaultPageLimit        = 20
+	DefaultMaxPageLimit     = 100
+	DefaultTotalCountHeader = "X-Total-Count"
+)
+
+// ErrInvalidPage is returned when a request asks for a negative limit or
+// offset.
+var ErrInvalidPage = &httperrors.Error{
+	HTTPCode: http.StatusBadRequest,
+	Err:      errors.New("invalid page"),
+	Code:     "INVALID_PAGE",
+}
+
+// ErrInvalidCursor is returned when a request carries a cursor that was not
+// issued by the engine, or was tampered with.
+var ErrInvalidCursor = &httperrors.Error{
+	HTTPCode: http.StatusBadRequest,
+	Err:      errors.New("invalid cursor"),
+	Code:     "INVALID_CURSOR",
+}
+
+// PaginationConfig defines the limits of Page parameters and the rendering of
+// Paginated results.
+type PaginationConfig struct {
+	// DefaultLimit is the limit of requests naming none, default is
+	// DefaultPageLimit.
+	// Optional.
+	DefaultLimit int
+
+	// MaxLimit caps the limit of requests, default is DefaultMaxPageLimit.
+	// Optional.
+	MaxLimit int
+
+	// CursorKey signs the cursors. Without it, cursors are signed with a
+	// random key of the process, which other instances and restarts reject.
+	// Optional.
+	CursorKey []byte
+
+	// TotalHeader carries the total count of Paginated results knowing it,
+	// default is DefaultTotalCountHeader.
+	// Optional.
+	TotalHeader string
+}
+
+// processCursorKey signs the cursors of engines without CursorKey.
+var processCursorKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// paginationConfig returns the pagination config of the engine with the
+// defaults applied. engine may be nil.
+func (engine *Engine) paginationConfig() PaginationConfig {
+	var config PaginationConfig
+	if engine != nil {
+		config = engine.Pagination
+	}
+	if config.DefaultLimit <= 0 {
+		config.DefaultLimit = DefaultPageLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = DefaultMaxPageLimit
+	}
+	if len(config.CursorKey) == 0 {
+		config.CursorKey = processCursorKey()
+	}
+	if config.TotalHeader == "" {
+		config.TotalHeader = DefaultTotalCountHeader
+	}
+	return config
+}
+
+// Page is a handler parameter bound from the "limit", "offset" and "cursor"
+// query parameters:
+//
+//	router.GET("/users", func(c *fox.Context, page fox.Page) (fox.Paginated[*User], error) {
+//		users, total, err := listUsers(c, page.Offset, page.Limit)
+//		if err != nil {
+//			return fox.Paginated[*User]{}, err
+//		}
+//		return fox.OffsetPage(page, users, total), nil
+//	})
+//
+// Limit defaults to PaginationConfig.DefaultLimit and is capped to
+// PaginationConfig.MaxLimit. Negative limits and offsets fail with
+// ErrInvalidPage, and cursors not issued by the engine with
+// ErrInvalidCursor.
+type Page struct {
+	FromQuery
+
+	Limit  int    `query:"limit" json:"limit"`
+	Offset int    `query:"offset" json:"offset,omitempty"`
+	Cursor string `query:"cursor" json:"cursor,omitempty"`
+
+	// cursor is the decoded value of Cursor.
+	cursor json.RawMessage
+}
+
+// completeBound applies the limits of the engine and decodes the cursor.
+func (p *Page) completeBound(c *Context) error {
+	if p.Limit < 0 || p.Offset < 0 {
+		return ErrInvalidPage
+	}
+	config := c.engine.paginationConfig()
+	if p.Limit == 0 {
+		p.Limit = config.DefaultLimit
+	}
+	p.Limit = min(p.Limit, config.MaxLimit)
+	if p.Cursor != "" {
+		if err := DecodeCursor(config.CursorKey, p.Cursor, &p.cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeCursor decodes the value of the cursor of the page into v, the next
+// or previous value of a CursorPage. It does nothing for pages without
+// cursor.
+func (p Page) DecodeCursor(v any) error {
+	if p.cursor == nil {
+		return nil
+	}
+	return json.Unmarshal(p.cursor, v)
+}
+
+// EncodeCursor returns the opaque cursor of value, encoded as JSON and signed
+// with key.
+func EncodeCursor(key []byte, value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	encoding := base64.RawURLEncoding
+	payload := encoding.EncodeToString(data)
+	return payload + "." + encoding.EncodeToString(hmacSHA256(cursorKey(key), payload)), nil
+}
+
+// DecodeCursor decodes a cursor of EncodeCursor into v. Cursors not signed
+// with key fail with ErrInvalidCursor.
+func DecodeCursor(key []byte, cursor string, v any) error {
+	encoding := base64.RawURLEncoding
+	payload, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+	sum, err := encoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sum, hmacSHA256(cursorKey(key), payload)) {
+		return ErrInvalidCursor
+	}
+	data, err := encoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(data, v) != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// cursorKey derives the key signing cursors, so that it is never shared with
+// other uses of the configured key.
+func cursorKey(key []byte) []byte {
+	return hmacSHA256(key, "fox pagination cursor")
+}
+
+// Paginated is a page of a collection. It is rendered as its Items, with a
+// RFC 8288 Link header to the first, previous, next and, when the total is
+// known, last pages, and the PaginationConfig.TotalHeader header carrying
+// the total. The pagination is also added to the "pagination" meta of the
+// Engine.Envelope.
+//
+// Build it with OffsetPage or CursorPage.
+type Paginated[T any] struct {
+	// Page is the requested page.
+	Page Page
+
+	// Items are the items of the page.
+	Items []T
+
+	// Total is the number of items of the collection, or negative when
+	// unknown.
+	Total int
+
+	// Next is the cursor value of the next page of a CursorPage, or nil on
+	// the last page.
+	Next any
+
+	// Prev is the cursor value of the previous page of a CursorPage, or nil
+	// on the first page.
+	Prev any
+
+	cursor bool
+}
+
+// OffsetPage returns the page of an offset paginated collection of total
+// items, negative when unknown. Without total, pages as long as the limit
+// link to a next page.
+func OffsetPage[T any](page Page, items []T, total int) Paginated[T] {
+	return Paginated[T]{Page: page, Items: items, Total: total}
+}
+
+// CursorPage returns the page of a cursor paginated collection, next being
+// the value of the cursor of the next page, such as the sort key of the last
+// item, or nil on the last page. The cursor value of a request is decoded
+// with Page.DecodeCursor.
+func CursorPage[T any](page Page, items []T, next any) Paginated[T] {
+	return Paginated[T]{Page: page, Items: items, Total: -1, Next: next, cursor: true}
+}
+
+// paginator is implemented by Paginated, which adds its pagination headers.
+type paginator interface {
+	paginate(c *Context)
+}
+
+func (p Paginated[T]) paginate(c *Context) {
+	config := c.engine.paginationConfig()
+	limit := p.Page.Limit
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	var links []string
+	// link adds the link to the request URL with the limit and, unless
+	// empty, the offset or cursor of the page.
+	link := func(rel, key, value string) {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Del("offset")
+		query.Del("cursor")
+		if value != "" {
+			query.Set(key, value)
+		}
+		u.RawQuery = query.Encode()
+		links = append(links, "<"+u.String()+`>; rel="`+rel+`"`)
+	}
+
+	meta := map[string]any{"limit": limit}
+	link("first", "", "")
+	if p.cursor {
+		for _, page := range []struct {
+			rel   string
+			value any
+		}{{"prev", p.Prev}, {"next", p.Next}} {
+			if page.value == nil {
+				continue
+			}
+			cursor, err := EncodeCursor(config.CursorKey, page.value)
+			if err != nil {
+				_ = c.Error(err)
+				continue
+			}
+			meta[page.rel+"_cursor"] = cursor
+			link(page.rel, "cursor", cursor)
+		}
+	} else {
+		offset := p.Page.Offset
+		meta["offset"] = offset
+		if offset > 0 {
+			link("prev", "offset", strconv.Itoa(max(offset-limit, 0)))
+		}
+		if p.Total >= 0 && offset+len(p.Items) < p.Total || p.Total < 0 && len(p.Items) >= limit {
+			link("next", "offset", strconv.Itoa(offset+limit))
+		}
+		if p.Total > 0 {
+			link("last", "offset", strconv.Itoa((p.Total-1)/limit*limit))
+		}
+	}
+	if p.Total >= 0 {
+		meta["total"] = p.Total
+		c.Header(config.TotalHeader, strconv.Itoa(p.Total))
+	}
+
+	c.Writer.Header().Add("Link", strings.Join(links, ", "))
+	SetEnvelopeMeta(c.Context, "pagination", meta)
+}
+
+func (p Paginated[T]) responseBody() any {
+	if p.Items == nil {
+		return []T{}
+	}
+	return p.Items
+}
+
+func (Paginated[T]) responseBodyType() reflect.Type {
+	return reflect.TypeFor[[]T]()
+}
@@ -0,0 +1,226 @@
+package fox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPaginationKey = []byte("pagination-test-key")
+
+// testItems are the items 1 to 45 of a paginated collection.
+func testItems(from, limit int) []int {
+	var items []int
+	for i := from + 1; i <= min(from+limit, 45); i++ {
+		items = append(items, i)
+	}
+	return items
+}
+
+func newPaginationRouter() *Engine {
+	router := New()
+	router.Pagination = PaginationConfig{DefaultLimit: 10, MaxLimit: 20, CursorKey: testPaginationKey}
+	router.GET("/items", func(c *Context, page Page) Paginated[int] {
+		return OffsetPage(page, testItems(page.Offset, page.Limit), 45)
+	})
+	router.GET("/stream", func(c *Context, page Page) (Paginated[int], error) {
+		var after int
+		if err := page.DecodeCursor(&after); err != nil {
+			return Paginated[int]{}, err
+		}
+		items := testItems(after, page.Limit)
+		var next any
+		if len(items) > 0 && items[len(items)-1] < 45 {
+			next = items[len(items)-1]
+		}
+		result := CursorPage(page, items, next)
+		if after > 0 {
+			result.Prev = max(after-page.Limit, 0)
+		}
+		return result, nil
+	})
+	return router
+}
+
+// parseLinks returns the targets of a Link header by relation.
+func parseLinks(header string) map[string]string {
+	links := map[string]string{}
+	for link := range strings.SplitSeq(header, ", ") {
+		target, rel, _ := strings.Cut(link, "; ")
+		links[strings.Trim(strings.TrimPrefix(rel, "rel="), `"`)] = strings.Trim(target, "<>")
+	}
+	return links
+}
+
+func TestPaginated_Offset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newPaginationRouter()
+
+	t.Run("first page", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/items?sort=name", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "[1,2,3,4,5,6,7,8,9,10]", w.Body.String())
+		assert.Equal(t, "45", w.Header().Get(DefaultTotalCountHeader))
+		assert.Equal(t, map[string]string{
+			"first": "/items?limit=10&sort=name",
+			"next":  "/items?limit=10&offset=10&sort=name",
+			"last":  "/items?limit=10&offset=40&sort=name",
+		}, parseLinks(w.Header().Get("Link")))
+	})
+
+	t.Run("middle page", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/items?offset=15&limit=5", "")
+		assert.Equal(t, "[16,17,18,19,20]", w.Body.String())
+		links := parseLinks(w.Header().Get("Link"))
+		assert.Equal(t, "/items?limit=5&offset=10", links["prev"])
+		assert.Equal(t, "/items?limit=5&offset=20", links["next"])
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/items?offset=40", "")
+		assert.Equal(t, "[41,42,43,44,45]", w.Body.String())
+		links := parseLinks(w.Header().Get("Link"))
+		assert.NotContains(t, links, "next")
+		assert.Equal(t, "/items?limit=10&offset=30", links["prev"])
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/items?limit=1000", "")
+		var items []int
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+		assert.Len(t, items, 20)
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/items?offset=-1", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_PAGE")
+	})
+}
+
+func TestPaginated_Cursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newPaginationRouter()
+
+	w := serve(router, http.MethodGet, "/stream?limit=20", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, w.Header().Get(DefaultTotalCountHeader))
+	links := parseLinks(w.Header().Get("Link"))
+	assert.Equal(t, "/stream?limit=20", links["first"])
+	assert.NotContains(t, links, "prev")
+	require.Contains(t, links, "next")
+
+	// Follow the next links to the end of the collection.
+	var items []int
+	for range 2 {
+		w = serve(router, http.MethodGet, links["next"], "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page []int
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		items = append(items, page...)
+		links = parseLinks(w.Header().Get("Link"))
+		assert.Contains(t, links, "prev")
+	}
+	assert.Equal(t, testItems(20, 25), items)
+	assert.NotContains(t, links, "next")
+
+	t.Run("tampered cursor", func(t *testing.T) {
+		cursor, err := EncodeCursor([]byte("other key"), 20)
+		require.NoError(t, err)
+		w := serve(router, http.MethodGet, "/stream?cursor="+url.QueryEscape(cursor), "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_CURSOR")
+
+		w = serve(router, http.MethodGet, "/stream?cursor=garbage", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPaginated_Envelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newPaginationRouter()
+	router.Envelope = DefaultEnvelope{}
+
+	w := serve(router, http.MethodGet, "/items?offset=40", "")
+	var body struct {
+		Data []int `json:"data"`
+		Meta struct {
+			Pagination map[string]any `json:"pagination"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, testItems(40, 10), body.Data)
+	assert.Equal(t, map[string]any{"limit": float64(10), "offset": float64(40), "total": float64(45)}, body.Meta.Pagination)
+}
+
+func TestPaginated_EmptyItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/empty", func(c *Context, page Page) Paginated[string] {
+		return OffsetPage[string](page, nil, 0)
+	})
+
+	w := serve(router, http.MethodGet, "/empty", "")
+	assert.Equal(t, "[]", w.Body.String())
+	assert.Equal(t, "0", w.Header().Get(DefaultTotalCountHeader))
+	assert.Equal(t, map[string]string{"first": "/empty?limit=20"}, parseLinks(w.Header().Get("Link")))
+}
+
+func TestPaginated_RouteManifest(t *testing.T) {
+	router := newPaginationRouter()
+	router.GET("/plain", func(c *Context) []int { return nil })
+
+	routes := map[string]RouteManifestRoute{}
+	for _, route := range RouteManifestFromEngine(router).Routes {
+		routes[route.Path] = route
+	}
+
+	items := routes["/items"]
+	assert.True(t, items.Paginated)
+	assert.Equal(t, []int{http.StatusOK}, items.Statuses)
+	require.Len(t, items.ResultTypes, 1)
+	assert.Equal(t, "slice", items.ResultTypes[0].Kind)
+	require.Len(t, items.Parameters, 1)
+	assert.Equal(t, "query", items.Parameters[0].Source)
+	assert.Equal(t, "Page", items.Parameters[0].Type.Name)
+
+	assert.True(t, routes["/stream"].Paginated)
+	assert.False(t, routes["/plain"].Paginated)
+}
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	type position struct {
+		ID    int    `json:"id"`
+		Since string `json:"since"`
+	}
+	cursor, err := EncodeCursor(testPaginationKey, position{ID: 7, Since: "2026-01-01"})
+	require.NoError(t, err)
+	assert.NotContains(t, cursor, "2026")
+
+	var got position
+	require.NoError(t, DecodeCursor(testPaginationKey, cursor, &got))
+	assert.Equal(t, position{ID: 7, Since: "2026-01-01"}, got)
+
+	assert.ErrorIs(t, DecodeCursor([]byte("other"), cursor, &got), ErrInvalidCursor)
+	assert.ErrorIs(t, DecodeCursor(testPaginationKey, cursor+"x", &got), ErrInvalidCursor)
+	assert.ErrorIs(t, DecodeCursor(testPaginationKey, "nodot", &got), ErrInvalidCursor)
+
+	_, err = EncodeCursor(testPaginationKey, func() {})
+	assert.Error(t, err)
+}
+
+func TestPage_DecodeCursorWithoutCursor(t *testing.T) {
+	after := 3
+	require.NoError(t, Page{}.DecodeCursor(&after))
+	assert.Equal(t, 3, after)
+}
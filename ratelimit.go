@@ -0,0 +1,186 @@
+package fox
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// RateLimitKeyFunc returns the key a request is counted under. An empty key
+// exempts the request from the limit.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByClientIP counts requests per client IP, as resolved by
+// gin.Context.ClientIP.
+func RateLimitByClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// RateLimitByHeader counts requests per value of the named header, for
+// example an API key. Requests without the header are counted per client IP.
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if value := c.GetHeader(name); value != "" {
+			return name + "=" + value
+		}
+		return c.ClientIP()
+	}
+}
+
+// RateLimitByContextValue counts requests per value stored in the gin context
+// under key, for example the authenticated user set by an auth middleware.
+// Requests without the value are counted per client IP.
+func RateLimitByContextValue(key string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if value, exists := c.Get(key); exists && value != nil {
+			return key + "=" + fmt.Sprint(value)
+		}
+		return c.ClientIP()
+	}
+}
+
+// RateLimitByRoute counts requests per route template, shared by all clients.
+func RateLimitByRoute(c *gin.Context) string {
+	return c.Request.Method + " " + c.FullPath()
+}
+
+// RateLimitKeys combines several key functions, for example client IP and
+// route to limit each client on each route independently.
+func RateLimitKeys(keys ...RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key(c)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// RateLimitConfig defines the config for RateLimit middleware.
+type RateLimitConfig struct {
+	// Limit is the number of requests allowed per Window. Required.
+	Limit int
+
+	// Window is the period Limit applies to. Required.
+	Window time.Duration
+
+	// Algorithm defaults to TokenBucket.
+	// Optional.
+	Algorithm RateLimitAlgorithm
+
+	// Key selects what requests are counted under, default is
+	// RateLimitByClientIP.
+	// Optional.
+	Key RateLimitKeyFunc
+
+	// Store keeps the counts, default is a new MemoryRateLimitStore. Share a
+	// store between limiters to bound its memory as a whole.
+	// Optional.
+	Store RateLimitStore
+
+	// Name identifies the limit in its store keys and route metadata, so that
+	// limits with the same rule do not share counts. Defaults to the rule,
+	// for example "100/1m0s".
+	// Optional.
+	Name string
+
+	// Error is rendered when the limit is exceeded, default is
+	// httperrors.ErrTooManyRequests.
+	// Optional.
+	Error *httperrors.Error
+}
+
+// RateLimit returns a middleware that limits how often a key, by default the
+// client IP, may call the routes it is attached to. It sets the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers and, when the limit is exceeded, answers with config.Error and a
+// Retry-After header. When several limits apply to a route, the headers
+// describe the one with the fewest remaining requests.
+//
+// Store errors are recorded with gin.Context.Error and the request is let
+// through. The limit is recorded in the "rateLimits" route metadata.
+//
+//	api := router.Group("/api", fox.RateLimit(fox.RateLimitConfig{Limit: 100, Window: time.Minute}))
+//	api.POST("/login", fox.RateLimit(fox.RateLimitConfig{
+//		Limit:     5,
+//		Window:    time.Minute,
+//		Algorithm: fox.SlidingWindow,
+//	}), Login)
+func RateLimit(config RateLimitConfig) *Middleware {
+	if config.Limit <= 0 || config.Window <= 0 {
+		panic("fox: rate limit requires a positive Limit and Window")
+	}
+	if config.Key == nil {
+		config.Key = RateLimitByClientIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+	if config.Name == "" {
+		config.Name = strconv.Itoa(config.Limit) + "/" + config.Window.String()
+	}
+	if config.Error == nil {
+		config.Error = httperrors.ErrTooManyRequests
+	}
+
+	rule := RateLimitRule{
+		Algorithm: config.Algorithm,
+		Limit:     config.Limit,
+		Window:    config.Window,
+	}
+	policy := fmt.Sprintf("%d;w=%d", config.Limit, ceilSeconds(config.Window))
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			key := config.Key(c)
+			if key == "" {
+				return
+			}
+
+			result, err := config.Store.Allow(c.Request.Context(), config.Name+":"+key, rule)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+
+			setRateLimitHeaders(c.Writer.Header(), config.Limit, policy, result)
+			if result.Allowed {
+				return
+			}
+
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			AbortWithError(c, config.Error)
+		},
+		Meta: RouteMeta{"rateLimits": RouteMetaList{map[string]any{
+			"name":      config.Name,
+			"limit":     config.Limit,
+			"window":    config.Window.String(),
+			"algorithm": config.Algorithm.String(),
+		}}},
+	}
+}
+
+func setRateLimitHeaders(header http.Header, limit int, policy string, result RateLimitResult) {
+	if prev := header.Get("RateLimit-Remaining"); prev != "" {
+		if remaining, err := strconv.Atoi(prev); err == nil && remaining <= result.Remaining {
+			return
+		}
+	}
+	header.Set("RateLimit-Limit", strconv.Itoa(limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	header.Set("RateLimit-Policy", policy)
+}
+
+// ceilSeconds rounds d up to whole seconds, as used by the rate limit and
+// Retry-After headers.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
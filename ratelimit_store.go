@@ -0,0 +1,285 @@
+package fox
+
+import (
+	"context"
+	"hash/maphash"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm selects how requests are counted.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts of up to Limit requests and refills Limit
+	// tokens evenly over Window.
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow allows Limit requests in any Window, approximated from
+	// the counts of the current and previous fixed windows.
+	SlidingWindow
+)
+
+// String returns the algorithm name used in route metadata.
+func (a RateLimitAlgorithm) String() string {
+	if a == SlidingWindow {
+		return "sliding_window"
+	}
+	return "token_bucket"
+}
+
+// RateLimitRule is the limit applied to one key.
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitResult is the outcome of a RateLimitStore.Allow call.
+type RateLimitResult struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+	// Remaining is the number of requests still allowed right now.
+	Remaining int
+	// Reset is the time until the quota is fully restored.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, set when the
+	// request is denied.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore records request counts. Implement it to keep limits in an
+// external backend shared by several instances, such as Redis.
+type RateLimitStore interface {
+	// Allow counts a request for key under rule and reports whether it is
+	// allowed.
+	Allow(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+const (
+	defaultRateLimitShards        = 64
+	defaultRateLimitSweepInterval = time.Minute
+)
+
+// MemoryRateLimitStoreConfig defines the config for MemoryRateLimitStore.
+type MemoryRateLimitStoreConfig struct {
+	// Shards is the number of independently locked shards, default is 64.
+	// Optional.
+	Shards int
+
+	// MaxKeys bounds the number of tracked keys; the least recently seen key
+	// of a full shard is evicted. Zero means unbounded.
+	// Optional.
+	MaxKeys int
+
+	// SweepInterval is how often a shard drops expired keys, default is 1m.
+	// Optional.
+	SweepInterval time.Duration
+}
+
+type rateLimitEntry struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+
+	// sliding window
+	windowStart time.Time
+	previous    int
+	current     int
+
+	seen    time.Time
+	expires time.Time
+}
+
+type rateLimitShard struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+// MemoryRateLimitStore is an in-process RateLimitStore with sharded locks and
+// eviction of idle keys.
+type MemoryRateLimitStore struct {
+	seed          maphash.Seed
+	shards        []rateLimitShard
+	maxPerShard   int
+	sweepInterval time.Duration
+
+	now func() time.Time
+}
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// NewMemoryRateLimitStore returns an in-memory RateLimitStore.
+func NewMemoryRateLimitStore(config ...MemoryRateLimitStoreConfig) *MemoryRateLimitStore {
+	var conf MemoryRateLimitStoreConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Shards <= 0 {
+		conf.Shards = defaultRateLimitShards
+	}
+	if conf.SweepInterval <= 0 {
+		conf.SweepInterval = defaultRateLimitSweepInterval
+	}
+
+	store := &MemoryRateLimitStore{
+		seed:          maphash.MakeSeed(),
+		shards:        make([]rateLimitShard, conf.Shards),
+		sweepInterval: conf.SweepInterval,
+		now:           time.Now,
+	}
+	if conf.MaxKeys > 0 {
+		store.maxPerShard = max(1, conf.MaxKeys/conf.Shards)
+	}
+	for i := range store.shards {
+		store.shards[i].entries = make(map[string]*rateLimitEntry)
+	}
+	return store
+}
+
+// Len returns the number of tracked keys.
+func (s *MemoryRateLimitStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		n += len(shard.entries)
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// Allow implements RateLimitStore.
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	if rule.Limit <= 0 || rule.Window <= 0 {
+		return RateLimitResult{Allowed: true}, nil
+	}
+
+	now := s.now()
+	shard := &s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if now.Sub(shard.lastSweep) >= s.sweepInterval {
+		shard.sweep(now)
+	}
+
+	entry, ok := shard.entries[key]
+	if !ok {
+		if s.maxPerShard > 0 && len(shard.entries) >= s.maxPerShard {
+			shard.evictOldest()
+		}
+		entry = &rateLimitEntry{}
+		shard.entries[key] = entry
+	}
+	entry.seen = now
+
+	if rule.Algorithm == SlidingWindow {
+		return entry.slidingWindow(now, rule), nil
+	}
+	return entry.tokenBucket(now, rule), nil
+}
+
+func (shard *rateLimitShard) sweep(now time.Time) {
+	shard.lastSweep = now
+	for key, entry := range shard.entries {
+		if !now.Before(entry.expires) {
+			delete(shard.entries, key)
+		}
+	}
+}
+
+func (shard *rateLimitShard) evictOldest() {
+	var (
+		oldestKey string
+		oldest    time.Time
+	)
+	for key, entry := range shard.entries {
+		if oldestKey == "" || entry.seen.Before(oldest) {
+			oldestKey, oldest = key, entry.seen
+		}
+	}
+	delete(shard.entries, oldestKey)
+}
+
+func (e *rateLimitEntry) tokenBucket(now time.Time, rule RateLimitRule) RateLimitResult {
+	capacity := float64(rule.Limit)
+	rate := capacity / rule.Window.Seconds() // tokens per second
+
+	if e.last.IsZero() {
+		e.tokens = capacity
+	} else {
+		e.tokens = math.Min(capacity, e.tokens+now.Sub(e.last).Seconds()*rate)
+	}
+	e.last = now
+
+	var result RateLimitResult
+	if e.tokens >= 1 {
+		e.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsDuration((1 - e.tokens) / rate)
+	}
+	result.Remaining = int(e.tokens)
+	result.Reset = secondsDuration((capacity - e.tokens) / rate)
+	e.expires = now.Add(result.Reset)
+	return result
+}
+
+func (e *rateLimitEntry) slidingWindow(now time.Time, rule RateLimitRule) RateLimitResult {
+	window := rule.Window
+	if e.windowStart.IsZero() {
+		e.windowStart = now.Truncate(window)
+	}
+	if elapsed := now.Sub(e.windowStart); elapsed >= window {
+		if elapsed >= 2*window {
+			e.previous = 0
+		} else {
+			e.previous = e.current
+		}
+		e.current = 0
+		e.windowStart = now.Truncate(window)
+	}
+
+	elapsed := now.Sub(e.windowStart)
+	weight := 1 - float64(elapsed)/float64(window)
+	estimate := float64(e.previous)*weight + float64(e.current)
+
+	var result RateLimitResult
+	if estimate+1 <= float64(rule.Limit) {
+		e.current++
+		estimate++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = e.slidingRetryAfter(elapsed, window, rule.Limit)
+	}
+	result.Remaining = max(0, int(float64(rule.Limit)-estimate))
+	// The previous window stops counting once the current one ends, and the
+	// current one a window later.
+	if e.current > 0 {
+		result.Reset = 2*window - elapsed
+	} else {
+		result.Reset = window - elapsed
+	}
+	e.expires = now.Add(result.Reset)
+	return result
+}
+
+// slidingRetryAfter returns when the weighted previous window has decayed
+// enough to allow one more request, or the end of the current window when the
+// current count alone exhausts the limit.
+func (e *rateLimitEntry) slidingRetryAfter(elapsed, window time.Duration, limit int) time.Duration {
+	room := float64(limit-1-e.current) / float64(max(e.previous, 1))
+	if e.current >= limit || room < 0 {
+		return window - elapsed
+	}
+	// previous*(1-(elapsed+t)/window) <= limit-1-current
+	wait := time.Duration((1-room)*float64(window)) - elapsed
+	return max(wait, time.Millisecond)
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
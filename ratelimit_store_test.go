@@ -0,0 +1,173 @@
+package fox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func TestMemoryRateLimitStore_TokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryRateLimitStore()
+	store.now = clock.Now
+	rule := RateLimitRule{Limit: 3, Window: 3 * time.Second}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Allow(ctx, "k", rule)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Allow(ctx, "k", rule)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// One token is refilled per second.
+	clock.Advance(time.Second)
+	result, _ = store.Allow(ctx, "k", rule)
+	assert.True(t, result.Allowed)
+	result, _ = store.Allow(ctx, "k", rule)
+	assert.False(t, result.Allowed)
+
+	// Other keys have their own bucket.
+	result, _ = store.Allow(ctx, "other", rule)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryRateLimitStore_SlidingWindow(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryRateLimitStore()
+	store.now = clock.Now
+	rule := RateLimitRule{Algorithm: SlidingWindow, Limit: 4, Window: 10 * time.Second}
+	ctx := context.Background()
+
+	for range 4 {
+		result, err := store.Allow(ctx, "k", rule)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	result, _ := store.Allow(ctx, "k", rule)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 10*time.Second, result.RetryAfter)
+
+	// Halfway through the next window, half of the previous count remains.
+	clock.Advance(15 * time.Second)
+	result, _ = store.Allow(ctx, "k", rule)
+	assert.True(t, result.Allowed)
+	result, _ = store.Allow(ctx, "k", rule)
+	assert.True(t, result.Allowed)
+	result, _ = store.Allow(ctx, "k", rule)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2500*time.Millisecond, result.RetryAfter)
+
+	// After two idle windows the count starts over.
+	clock.Advance(20 * time.Second)
+	result, _ = store.Allow(ctx, "k", rule)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 3, result.Remaining)
+}
+
+func TestMemoryRateLimitStore_Eviction(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryRateLimitStore(MemoryRateLimitStoreConfig{Shards: 1, MaxKeys: 2})
+	store.now = clock.Now
+	rule := RateLimitRule{Limit: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	_, _ = store.Allow(ctx, "a", rule)
+	clock.Advance(time.Millisecond)
+	_, _ = store.Allow(ctx, "b", rule)
+	clock.Advance(time.Millisecond)
+	_, _ = store.Allow(ctx, "c", rule)
+	assert.Equal(t, 2, store.Len())
+
+	// "a" was evicted, so it starts with a full bucket again.
+	result, _ := store.Allow(ctx, "a", rule)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryRateLimitStore_Sweep(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryRateLimitStore(MemoryRateLimitStoreConfig{Shards: 1, SweepInterval: time.Second})
+	store.now = clock.Now
+	rule := RateLimitRule{Limit: 10, Window: time.Second}
+	ctx := context.Background()
+
+	for i := range 5 {
+		_, _ = store.Allow(ctx, fmt.Sprint(i), rule)
+	}
+	assert.Equal(t, 5, store.Len())
+
+	clock.Advance(2 * time.Second)
+	_, _ = store.Allow(ctx, "new", rule)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestMemoryRateLimitStore_Concurrent(t *testing.T) {
+	store := NewMemoryRateLimitStore(MemoryRateLimitStoreConfig{Shards: 4})
+	rule := RateLimitRule{Limit: 50, Window: time.Hour}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Allow(context.Background(), "shared", rule)
+			assert.NoError(t, err)
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, allowed)
+}
+
+func TestMemoryRateLimitStore_NoLimit(t *testing.T) {
+	result, err := NewMemoryRateLimitStore().Allow(context.Background(), "k", RateLimitRule{})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimitAlgorithm_String(t *testing.T) {
+	assert.Equal(t, "token_bucket", TokenBucket.String())
+	assert.Equal(t, "sliding_window", SlidingWindow.String())
+}
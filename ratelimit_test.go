@@ -0,0 +1,176 @@
+package fox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit_Headers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/ping", RateLimit(RateLimitConfig{Limit: 2, Window: time.Minute}), func() string {
+		return "pong"
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	w = serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = serve()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "TOO_MANY_REQUESTS", body["code"])
+}
+
+func TestRateLimit_KeyFuncs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRequest := func(remoteAddr string, header map[string]string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.RemoteAddr = remoteAddr
+		for k, v := range header {
+			c.Request.Header.Set(k, v)
+		}
+		return c
+	}
+
+	c := newRequest("10.0.0.1:1", nil)
+	assert.Equal(t, "10.0.0.1", RateLimitByClientIP(c))
+	assert.Equal(t, "10.0.0.1", RateLimitByHeader("X-API-Key")(c))
+	assert.Equal(t, "10.0.0.1", RateLimitByContextValue("user")(c))
+
+	c = newRequest("10.0.0.1:1", map[string]string{"X-API-Key": "secret"})
+	assert.Equal(t, "X-API-Key=secret", RateLimitByHeader("X-API-Key")(c))
+
+	c.Set("user", 42)
+	assert.Equal(t, "user=42", RateLimitByContextValue("user")(c))
+	assert.Equal(t, "user=42|10.0.0.1", RateLimitKeys(RateLimitByContextValue("user"), RateLimitByClientIP)(c))
+}
+
+func TestRateLimit_GroupAndRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	api := router.Group("/api", RateLimit(RateLimitConfig{Limit: 10, Window: time.Minute}))
+	api.GET("/list", func() string { return "ok" })
+	api.POST("/login", RateLimit(RateLimitConfig{
+		Limit:     1,
+		Window:    time.Minute,
+		Algorithm: SlidingWindow,
+		Key:       RateLimitKeys(RateLimitByClientIP, RateLimitByRoute),
+	}), func() string { return "ok" })
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/api/login")
+	assert.Equal(t, http.StatusOK, w.Code)
+	// The stricter route limit is reported.
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = serve(http.MethodPost, "/api/login")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// The group limit still has room.
+	w = serve(http.MethodGet, "/api/list")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "7", w.Header().Get("RateLimit-Remaining"))
+
+	meta := map[string]RouteMeta{}
+	for _, route := range router.HandlerRoutes() {
+		meta[route.Path] = route.Meta
+	}
+	assert.Equal(t, RouteMetaList{
+		map[string]any{"name": "10/1m0s", "limit": 10, "window": "1m0s", "algorithm": "token_bucket"},
+	}, meta["/api/list"]["rateLimits"])
+	assert.Equal(t, RouteMetaList{
+		map[string]any{"name": "10/1m0s", "limit": 10, "window": "1m0s", "algorithm": "token_bucket"},
+		map[string]any{"name": "1/1m0s", "limit": 1, "window": "1m0s", "algorithm": "sliding_window"},
+	}, meta["/api/login"]["rateLimits"])
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Allow(context.Context, string, RateLimitRule) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store unavailable")
+}
+
+func TestRateLimit_StoreErrorFailsOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var errs []*gin.Error
+	router := New()
+	router.Use(gin.HandlerFunc(func(c *gin.Context) {
+		c.Next()
+		errs = c.Errors
+	}))
+	router.GET("/ping", RateLimit(RateLimitConfig{
+		Limit:  1,
+		Window: time.Second,
+		Store:  failingRateLimitStore{},
+	}), func() string { return "pong" })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0].Err, "store unavailable")
+}
+
+func TestRateLimit_EmptyKeyIsExempt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/ping", RateLimit(RateLimitConfig{
+		Limit:  1,
+		Window: time.Minute,
+		Key:    func(*gin.Context) string { return "" },
+	}), func() string { return "pong" })
+
+	for range 3 {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimit_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() { RateLimit(RateLimitConfig{Window: time.Second}) })
+	assert.Panics(t, func() { RateLimit(RateLimitConfig{Limit: 1}) })
+}
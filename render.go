@@ -2,12 +2,62 @@ package fox
 
 import (
 	"encoding/json"
+	"maps"
 	"net/http"
 
+	"github.com/gin-gonic/gin"
+
 	"github.com/fox-gonic/fox/render"
 )
 
-// StatusCoder is a interface for http status code
+// templateDataContextKey stores the values added by SetTemplateData.
+const templateDataContextKey = "_fox-gonic/fox/template-data"
+
+// SetTemplateData adds a value to the data of render.HTML results rendered
+// for the request, so that middleware can expose per-request values such as
+// a CSRF token to templates. It is added when the handler's Data is nil, a
+// map[string]any or gin.H without that key.
+func SetTemplateData(c *gin.Context, key string, value any) {
+	data, _ := c.Get(templateDataContextKey)
+	values, _ := data.(map[string]any)
+	if values == nil {
+		values = make(map[string]any)
+		c.Set(templateDataContextKey, values)
+	}
+	values[key] = value
+}
+
+// withTemplateData returns r with the values of SetTemplateData added to its
+// data.
+func withTemplateData(c *gin.Context, r render.HTML) render.HTML {
+	v, _ := c.Get(templateDataContextKey)
+	values, _ := v.(map[string]any)
+	if len(values) == 0 {
+		return r
+	}
+
+	var data map[string]any
+	switch d := r.Data.(type) {
+	case nil:
+		data = make(map[string]any, len(values))
+	case map[string]any:
+		data = maps.Clone(d)
+	case gin.H:
+		data = maps.Clone(d)
+	default:
+		return r
+	}
+	for key, value := range values {
+		if _, exists := data[key]; !exists {
+			data[key] = value
+		}
+	}
+	r.Data = data
+	return r
+}
+
+// StatusCoder is a interface for http status code, of errors and handler
+// results
 type StatusCoder interface {
 	StatusCode() int
 }
@@ -36,6 +86,15 @@ func (c *Context) renderError(err error) {
 		return
 	}
 
+	if envelope := c.envelope(); envelope != nil {
+		var body any = err.Error()
+		if e, ok := err.(json.Marshaler); ok {
+			body = e
+		}
+		c.JSON(code, envelope.WrapError(c, err, body))
+		return
+	}
+
 	if e, ok := err.(json.Marshaler); ok {
 		c.JSON(code, e)
 	} else {
@@ -43,23 +102,64 @@ func (c *Context) renderError(err error) {
 	}
 }
 
-// render auto render
+// envelope returns the Envelope of the engine, or nil.
+func (c *Context) envelope() Envelope {
+	if c.engine == nil {
+		return nil
+	}
+	return c.engine.Envelope
+}
+
+// render auto render, with the status code and headers of results
+// implementing StatusCoder and Headerer, and the pagination headers of
+// Paginated results.
 func (c *Context) render(res any) {
 	if res == nil {
 		return
 	}
 
+	code := http.StatusOK
+	if _, ok := res.(error); !ok {
+		if p, ok := res.(paginator); ok {
+			p.paginate(c)
+		}
+		if h, ok := res.(Headerer); ok {
+			header := c.Writer.Header()
+			for key, values := range h.Headers() {
+				for _, value := range values {
+					header.Add(key, value)
+				}
+			}
+		}
+		if s, ok := res.(StatusCoder); ok && s.StatusCode() != 0 {
+			code = s.StatusCode()
+		}
+		if r, ok := res.(responseBodier); ok {
+			if res = r.responseBody(); res == nil {
+				c.Status(code)
+				c.Abort()
+				return
+			}
+		}
+	}
+
 	switch r := res.(type) {
 	case error:
 		c.renderError(r)
 	case string:
-		c.String(http.StatusOK, r)
+		c.String(code, r)
 	case render.Redirect:
 		c.Redirect(r.Code, r.Location)
+	case render.HTML:
+		c.Render(code, withTemplateData(c.Context, r))
 	case render.Render:
-		c.Render(http.StatusOK, r)
+		c.Render(code, r)
 	default:
-		c.JSON(http.StatusOK, r)
+		if envelope := c.envelope(); envelope != nil {
+			c.JSON(code, envelope.Wrap(c, r))
+		} else {
+			c.JSON(code, r)
+		}
 	}
 
 	c.Abort()
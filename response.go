@@ -0,0 +1,168 @@
+package fox
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headerer is implemented by handler results that set response headers.
+type Headerer interface {
+	Headers() http.Header
+}
+
+// Response is a handler result rendered with its status code and headers,
+// so that handlers can answer 201, 202 or 204 without touching the writer:
+//
+//	router.POST("/users", func(c *fox.Context, args CreateUser) (fox.Response[*User], error) {
+//		user, err := create(c, args)
+//		if err != nil {
+//			return fox.Response[*User]{}, err
+//		}
+//		return fox.Created("/users/"+user.ID, user), nil
+//	})
+//
+// Body is rendered like any other handler result. A nil Body renders no
+// response body.
+//
+// The status of a Response is known at run time only, declare it with
+// Responds for the route manifest.
+type Response[T any] struct {
+	// Status is the HTTP status code, default is 200.
+	Status int
+
+	// Header is added to the response headers.
+	Header http.Header
+
+	// Body is the rendered result.
+	Body T
+}
+
+// StatusCode implements StatusCoder.
+func (r Response[T]) StatusCode() int {
+	if r.Status == 0 {
+		return http.StatusOK
+	}
+	return r.Status
+}
+
+// Headers implements Headerer.
+func (r Response[T]) Headers() http.Header {
+	return r.Header
+}
+
+// responseBody returns the Body, or nil for nil pointers, maps, slices and
+// interfaces, which render no response body.
+func (r Response[T]) responseBody() any {
+	switch v := reflect.ValueOf(&r.Body).Elem(); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return r.Body
+}
+
+func (Response[T]) responseBodyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// responseBodier is implemented by Response, which renders its Body.
+type responseBodier interface {
+	responseBody() any
+	responseBodyType() reflect.Type
+}
+
+var responseBodierType = reflect.TypeFor[responseBodier]()
+
+// responseBodyType returns the Body type of a Response result type.
+func responseBodyType(typ reflect.Type) (reflect.Type, bool) {
+	if !typ.Implements(responseBodierType) || typ.Kind() == reflect.Interface {
+		return nil, false
+	}
+	v := reflect.Zero(typ)
+	if typ.Kind() == reflect.Ptr {
+		v = reflect.New(typ.Elem())
+	}
+	return v.Interface().(responseBodier).responseBodyType(), true
+}
+
+// Created returns a 201 Created response with the Location header.
+func Created[T any](location string, body T) Response[T] {
+	r := Response[T]{Status: http.StatusCreated, Body: body}
+	if location != "" {
+		r.Header = http.Header{"Location": {location}}
+	}
+	return r
+}
+
+// Accepted returns a 202 Accepted response.
+func Accepted[T any](body T) Response[T] {
+	return Response[T]{Status: http.StatusAccepted, Body: body}
+}
+
+// NoContent returns a 204 No Content response.
+func NoContent() Response[any] {
+	return Response[any]{Status: http.StatusNoContent}
+}
+
+// Responds declares the success statuses of the routes it is attached to,
+// for the route manifest. It does nothing at run time.
+//
+//	router.POST("/jobs", fox.Responds(http.StatusAccepted, http.StatusNoContent), StartJob)
+func Responds(statuses ...int) *Middleware {
+	list := make(RouteMetaList, len(statuses))
+	for i, status := range statuses {
+		list[i] = status
+	}
+	return &Middleware{
+		Handler: func(*gin.Context) {},
+		Meta:    RouteMeta{"statuses": list},
+	}
+}
+
+// resultStatus returns the status of a handler result type known before
+// the handler runs, or 0.
+func resultStatus(typ reflect.Type) int {
+	if typ.Implements(errorType) {
+		return 0
+	}
+	if !typ.Implements(reflect.TypeFor[StatusCoder]()) {
+		return http.StatusOK
+	}
+	// The status of a Response is known at run time only.
+	if typ.Implements(responseBodierType) {
+		return 0
+	}
+	v := reflect.Zero(typ)
+	if typ.Kind() == reflect.Ptr {
+		v = reflect.New(typ.Elem())
+	}
+	if v.Kind() == reflect.Interface {
+		return 0
+	}
+	return v.Interface().(StatusCoder).StatusCode()
+}
+
+// routeStatuses returns the sorted success statuses of a route, from its
+// handler result types and Responds.
+func routeStatuses(route RouteInfo) []int {
+	var statuses []int
+	if typ := route.HandlerType; typ != nil && typ.Kind() == reflect.Func {
+		for i := range typ.NumOut() {
+			if status := resultStatus(typ.Out(i)); status != 0 {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	declared, _ := route.Meta["statuses"].(RouteMetaList)
+	for _, status := range declared {
+		if status, ok := status.(int); ok {
+			statuses = append(statuses, status)
+		}
+	}
+	slices.Sort(statuses)
+	return slices.Compact(statuses)
+}
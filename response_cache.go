@@ -0,0 +1,156 @@
+package fox
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultResponseCacheSize is the default size limit of a ResponseCache, 64MB.
+const DefaultResponseCacheSize = 64 << 20
+
+// cachedResponse is a response stored in a ResponseCache.
+type cachedResponse struct {
+	key     string
+	status  int
+	header  http.Header
+	body    []byte
+	tags    []string
+	expires time.Time
+	size    int
+}
+
+// ResponseCache is an in-memory LRU cache of rendered responses used by the
+// Cache middleware. It is bounded by the total size of the cached bodies and
+// headers; the least recently used responses are evicted first.
+type ResponseCache struct {
+	mu      sync.Mutex
+	maxSize int
+	size    int
+	lru     *list.List // of *cachedResponse, most recently used first
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+
+	now func() time.Time
+}
+
+// NewResponseCache returns a ResponseCache holding up to maxSize bytes,
+// default is DefaultResponseCacheSize.
+func NewResponseCache(maxSize ...int) *ResponseCache {
+	size := DefaultResponseCacheSize
+	if len(maxSize) > 0 && maxSize[0] > 0 {
+		size = maxSize[0]
+	}
+	return &ResponseCache{
+		maxSize: size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		tags:    make(map[string]map[string]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Len returns the number of cached responses.
+func (rc *ResponseCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.lru.Len()
+}
+
+// Size returns the total size of the cached responses in bytes.
+func (rc *ResponseCache) Size() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.size
+}
+
+// Invalidate removes every response tagged with one of tags, see
+// Context.CacheTags.
+func (rc *ResponseCache) Invalidate(tags ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, tag := range tags {
+		for key := range rc.tags[tag] {
+			if elem, ok := rc.entries[key]; ok {
+				rc.remove(elem)
+			}
+		}
+	}
+}
+
+// Purge removes all responses.
+func (rc *ResponseCache) Purge() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.lru.Init()
+	rc.size = 0
+	clear(rc.entries)
+	clear(rc.tags)
+}
+
+func (rc *ResponseCache) get(key string) *cachedResponse {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*cachedResponse)
+	if !entry.expires.IsZero() && !rc.now().Before(entry.expires) {
+		rc.remove(elem)
+		return nil
+	}
+	rc.lru.MoveToFront(elem)
+	return entry
+}
+
+func (rc *ResponseCache) set(entry *cachedResponse, ttl time.Duration) {
+	entry.size = len(entry.key) + len(entry.body)
+	for name, values := range entry.header {
+		entry.size += len(name)
+		for _, value := range values {
+			entry.size += len(value)
+		}
+	}
+	if entry.size > rc.maxSize {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if ttl > 0 {
+		entry.expires = rc.now().Add(ttl)
+	}
+	if elem, ok := rc.entries[entry.key]; ok {
+		rc.remove(elem)
+	}
+	for rc.size+entry.size > rc.maxSize {
+		rc.remove(rc.lru.Back())
+	}
+
+	rc.entries[entry.key] = rc.lru.PushFront(entry)
+	rc.size += entry.size
+	for _, tag := range entry.tags {
+		keys, ok := rc.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			rc.tags[tag] = keys
+		}
+		keys[entry.key] = struct{}{}
+	}
+}
+
+func (rc *ResponseCache) remove(elem *list.Element) {
+	entry := rc.lru.Remove(elem).(*cachedResponse)
+	delete(rc.entries, entry.key)
+	rc.size -= entry.size
+	for _, tag := range entry.tags {
+		delete(rc.tags[tag], entry.key)
+		if len(rc.tags[tag]) == 0 {
+			delete(rc.tags, tag)
+		}
+	}
+}
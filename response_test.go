@@ -0,0 +1,150 @@
+package fox
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+type testJob struct {
+	ID string `json:"id"`
+}
+
+// testJobQueued is rendered with its own status and headers.
+type testJobQueued struct {
+	ID string `json:"id"`
+}
+
+func (testJobQueued) StatusCode() int { return http.StatusAccepted }
+
+func (j testJobQueued) Headers() http.Header {
+	return http.Header{"Retry-After": {"5"}, "Location": {"/jobs/" + j.ID}}
+}
+
+func TestResponse_Render(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.POST("/jobs", func(c *Context) (Response[*testJob], error) {
+		return Created("/jobs/1", &testJob{ID: "1"}), nil
+	})
+	router.PUT("/jobs/:id", func(c *Context) Response[string] {
+		return Accepted("queued")
+	})
+	router.DELETE("/jobs/:id", func(c *Context) (Response[any], error) {
+		return NoContent(), nil
+	})
+	router.GET("/jobs/:id", func(c *Context) Response[*testJob] {
+		return Response[*testJob]{
+			Header: http.Header{"Cache-Control": {"no-store"}},
+			Body:   &testJob{ID: c.Param("id")},
+		}
+	})
+	router.POST("/jobs/:id/retry", func(c *Context) Response[*testJob] {
+		return Response[*testJob]{Status: http.StatusAccepted}
+	})
+	router.PATCH("/jobs/:id", func(c *Context) testJobQueued {
+		return testJobQueued{ID: c.Param("id")}
+	})
+
+	t.Run("created", func(t *testing.T) {
+		w := serve(router, http.MethodPost, "/jobs", "")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/jobs/1", w.Header().Get("Location"))
+		assert.JSONEq(t, `{"id":"1"}`, w.Body.String())
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		w := serve(router, http.MethodPut, "/jobs/1", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "queued", w.Body.String())
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		w := serve(router, http.MethodDelete, "/jobs/1", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("nil pointer body", func(t *testing.T) {
+		w := serve(router, http.MethodPost, "/jobs/1/retry", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("default status", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/jobs/2", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"id":"2"}`, w.Body.String())
+	})
+
+	t.Run("status and headers of the result type", func(t *testing.T) {
+		w := serve(router, http.MethodPatch, "/jobs/3", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "5", w.Header().Get("Retry-After"))
+		assert.Equal(t, "/jobs/3", w.Header().Get("Location"))
+		assert.JSONEq(t, `{"id":"3"}`, w.Body.String())
+	})
+}
+
+func TestResponse_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.POST("/jobs", func(c *Context) (Response[*testJob], error) {
+		return Created("/jobs/1", &testJob{ID: "1"}), httperrors.ErrForbidden
+	})
+
+	w := serve(router, http.MethodPost, "/jobs", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+}
+
+func TestResponse_RouteManifest(t *testing.T) {
+	router := New()
+	router.POST("/jobs", Responds(http.StatusCreated), func(c *Context) (Response[*testJob], error) { return Response[*testJob]{}, nil })
+	router.DELETE("/jobs/:id", Responds(http.StatusNoContent), func(c *Context) (Response[any], error) { return NoContent(), nil })
+	router.GET("/jobs/:id", func(c *Context) (*testJob, error) { return nil, nil })
+	router.PATCH("/jobs/:id", func(c *Context) testJobQueued { return testJobQueued{} })
+	router.PUT("/jobs/:id", func(c *Context) {})
+
+	statuses := map[string][]int{}
+	results := map[string]string{}
+	for _, route := range RouteManifestFromEngine(router).Routes {
+		statuses[route.Method] = route.Statuses
+		if len(route.ResultTypes) > 0 {
+			results[route.Method] = route.ResultTypes[0].Kind
+			if elem := route.ResultTypes[0].Elem; elem != nil {
+				results[route.Method] += " " + elem.Name
+			}
+		}
+	}
+	assert.Equal(t, map[string][]int{
+		"POST":   {http.StatusCreated},
+		"DELETE": {http.StatusNoContent},
+		"GET":    {http.StatusOK},
+		"PATCH":  {http.StatusAccepted},
+		"PUT":    nil,
+	}, statuses)
+
+	// Response results are described by their body.
+	assert.Equal(t, "ptr testJob", results["POST"])
+	assert.Equal(t, "interface", results["DELETE"])
+}
+
+func TestResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, Response[string]{}.StatusCode())
+	assert.Equal(t, http.StatusCreated, Created("", 1).StatusCode())
+	assert.Nil(t, Created("", 1).Headers())
+
+	typ, ok := responseBodyType(reflect.TypeFor[*Response[testJob]]())
+	require.True(t, ok)
+	assert.Equal(t, reflect.TypeFor[testJob](), typ)
+}
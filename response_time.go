@@ -1,6 +1,10 @@
 package fox
 
 import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -9,28 +13,117 @@ import (
 
 const headerXResponseTime = "X-Response-Time"
 
-// XResponseTimer wrap gin response writer add start time
+// XResponseTimer wrap gin response writer add start time.
+//
+// The "start, nanoseconds" value is written as a header right before the
+// response header is committed, whether that happens through WriteHeader, an
+// implicit 200 on the first Write, a Flush or ReadFrom. Streamed (flushed)
+// responses additionally carry the total time as an HTTP trailer of the same
+// name. Hijacked connections get no timing.
 type XResponseTimer struct {
 	gin.ResponseWriter
 	start time.Time
 	key   string
+
+	streamed bool
+	hijacked bool
 }
 
-// WriteHeader implement http.ResponseWriter
-func (w *XResponseTimer) WriteHeader(statusCode int) {
+var (
+	_ http.Flusher  = (*XResponseTimer)(nil)
+	_ http.Hijacker = (*XResponseTimer)(nil)
+	_ http.Pusher   = (*XResponseTimer)(nil)
+	_ io.ReaderFrom = (*XResponseTimer)(nil)
+)
+
+func (w *XResponseTimer) value() string {
 	buf := make([]byte, 0, 40)
 	buf = strconv.AppendInt(buf, w.start.UnixMilli(), 10)
 	buf = append(buf, ',', ' ')
 	buf = strconv.AppendInt(buf, time.Since(w.start).Nanoseconds(), 10)
-	w.Header().Set(w.key, string(buf))
+	return string(buf)
+}
+
+// setHeader updates the header while it can still be sent.
+func (w *XResponseTimer) setHeader() {
+	if !w.hijacked && !w.Written() {
+		w.Header().Set(w.key, w.value())
+	}
+}
+
+// WriteHeader implement http.ResponseWriter
+func (w *XResponseTimer) WriteHeader(statusCode int) {
+	w.setHeader()
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// WriteHeaderNow implement gin.ResponseWriter
+func (w *XResponseTimer) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
 // Write implement http.ResponseWriter
 func (w *XResponseTimer) Write(b []byte) (int, error) {
+	w.setHeader()
 	return w.ResponseWriter.Write(b)
 }
 
+// WriteString implement io.StringWriter
+func (w *XResponseTimer) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ReadFrom implement io.ReaderFrom, using the wrapped writer's ReadFrom when
+// it has one so that sendfile and similar fast paths are kept.
+func (w *XResponseTimer) ReadFrom(r io.Reader) (int64, error) {
+	w.setHeader()
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	// Hide ReadFrom from io.Copy to avoid recursing into this method.
+	return io.Copy(struct{ io.Writer }{w.ResponseWriter}, r)
+}
+
+// Flush implement http.Flusher. Flushing marks the response as streamed, so
+// that the total time is sent as a trailer when the handler returns.
+func (w *XResponseTimer) Flush() {
+	w.setHeader()
+	w.streamed = true
+	w.ResponseWriter.Flush()
+}
+
+// Hijack implement http.Hijacker
+func (w *XResponseTimer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Push implement http.Pusher
+func (w *XResponseTimer) Push(target string, opts *http.PushOptions) error {
+	if pusher := w.ResponseWriter.Pusher(); pusher != nil {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// finish runs once the handler chain returned. Responses without a body are
+// committed by gin after the chain, bypassing the wrapper, so their header is
+// set here; streamed responses get the total time as a trailer.
+func (w *XResponseTimer) finish() {
+	switch {
+	case w.hijacked:
+	case !w.Written():
+		w.setHeader()
+	case w.streamed:
+		w.Header().Set(http.TrailerPrefix+w.key, w.value())
+	}
+}
+
 // NewXResponseTimer x-response-time middleware
 func NewXResponseTimer(key ...string) gin.HandlerFunc {
 	k := headerXResponseTime
@@ -38,11 +131,13 @@ func NewXResponseTimer(key ...string) gin.HandlerFunc {
 		k = key[0]
 	}
 	return func(c *gin.Context) {
-		c.Writer = &XResponseTimer{
+		timer := &XResponseTimer{
 			ResponseWriter: c.Writer,
 			start:          time.Now(),
 			key:            k,
 		}
+		c.Writer = timer
 		c.Next()
+		timer.finish()
 	}
 }
@@ -1,6 +1,9 @@
 package fox
 
 import (
+	"bufio"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -460,3 +463,204 @@ func BenchmarkNewXResponseTimer_Middleware(b *testing.B) {
 		router.ServeHTTP(w, req)
 	}
 }
+
+// Test response paths that bypass WriteHeader
+
+func TestNewXResponseTimer_ImplicitHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewXResponseTimer())
+	router.GET("/write", func(c *gin.Context) {
+		_, _ = c.Writer.Write([]byte("implicit"))
+	})
+	router.GET("/write-string", func(c *gin.Context) {
+		_, _ = c.Writer.WriteString("implicit")
+	})
+	router.GET("/no-body", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/nothing", func(*gin.Context) {})
+
+	for _, path := range []string{"/write", "/write-string", "/no-body", "/nothing"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			router.ServeHTTP(w, req)
+
+			parts := strings.Split(w.Header().Get(headerXResponseTime), ", ")
+			require.Len(t, parts, 2)
+		})
+	}
+}
+
+func TestNewXResponseTimer_ReadFrom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewXResponseTimer())
+	router.GET("/copy", func(c *gin.Context) {
+		_, _ = io.Copy(c.Writer, strings.NewReader("copied body"))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/copy", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "copied body", w.Body.String())
+	assert.NotEmpty(t, w.Header().Get(headerXResponseTime))
+}
+
+type readerFromWriter struct {
+	gin.ResponseWriter
+	readFrom bool
+}
+
+func (w *readerFromWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(struct{ io.Writer }{w.ResponseWriter}, r)
+}
+
+func TestXResponseTimer_ReadFromUsesWrappedReaderFrom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(w)
+	inner := &readerFromWriter{ResponseWriter: ginCtx.Writer}
+
+	timer := &XResponseTimer{ResponseWriter: inner, start: time.Now(), key: headerXResponseTime}
+	n, err := timer.ReadFrom(strings.NewReader("data"))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.True(t, inner.readFrom)
+	assert.Equal(t, "data", w.Body.String())
+	assert.NotEmpty(t, w.Header().Get(headerXResponseTime))
+}
+
+func TestNewXResponseTimer_StreamingTrailer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewXResponseTimer())
+	router.GET("/stream", func(c *gin.Context) {
+		for i := 0; i < 3; i++ {
+			_, _ = c.Writer.WriteString("chunk\n")
+			c.Writer.Flush()
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	router.ServeHTTP(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	header := res.Header.Get(headerXResponseTime)
+	require.NotEmpty(t, header)
+	trailer := res.Trailer.Get(headerXResponseTime)
+	require.NotEmpty(t, trailer)
+
+	headerParts := strings.Split(header, ", ")
+	trailerParts := strings.Split(trailer, ", ")
+	require.Len(t, trailerParts, 2)
+	assert.Equal(t, headerParts[0], trailerParts[0])
+
+	headerNanos, err := strconv.ParseInt(headerParts[1], 10, 64)
+	require.NoError(t, err)
+	trailerNanos, err := strconv.ParseInt(trailerParts[1], 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, trailerNanos, headerNanos+int64(2*time.Millisecond))
+}
+
+func TestNewXResponseTimer_NonStreamingHasNoTrailer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewXResponseTimer())
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	router.ServeHTTP(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Empty(t, res.Trailer)
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	server net.Conn
+	client net.Conn
+}
+
+func newHijackRecorder() *hijackRecorder {
+	server, client := net.Pipe()
+	return &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), server: server, client: client}
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.server, bufio.NewReadWriter(bufio.NewReader(r.server), bufio.NewWriter(r.server)), nil
+}
+
+func TestNewXResponseTimer_Hijack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewXResponseTimer())
+	router.GET("/ws", func(c *gin.Context) {
+		conn, _, err := c.Writer.Hijack()
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		_ = conn.Close()
+	})
+
+	w := newHijackRecorder()
+	defer w.client.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get(headerXResponseTime))
+	assert.Empty(t, w.Header().Get(http.TrailerPrefix+headerXResponseTime))
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (r *pushRecorder) Push(target string, _ *http.PushOptions) error {
+	r.pushed = append(r.pushed, target)
+	return nil
+}
+
+func TestXResponseTimer_Push(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewXResponseTimer())
+
+	var pushErr error
+	router.GET("/push", func(c *gin.Context) {
+		pusher, ok := c.Writer.(http.Pusher)
+		require.True(t, ok)
+		pushErr = pusher.Push("/style.css", nil)
+		c.Status(http.StatusOK)
+	})
+
+	w := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/push", nil))
+	require.NoError(t, pushErr)
+	assert.Equal(t, []string{"/style.css"}, w.pushed)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/push", nil))
+	assert.ErrorIs(t, pushErr, http.ErrNotSupported)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -28,11 +29,30 @@ type RouteManifest struct {
 // RouteManifestRoute describes one Fox route and the original business handler
 // captured at registration time.
 type RouteManifestRoute struct {
-	Method      string              `json:"method"`
-	Path        string              `json:"path"`
-	Handler     string              `json:"handler,omitempty"`
-	InputTypes  []RouteManifestType `json:"inputTypes,omitempty"`
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	Handler    string              `json:"handler,omitempty"`
+	Version    string              `json:"version,omitempty"`
+	Deprecated bool                `json:"deprecated,omitempty"`
+	InputTypes []RouteManifestType `json:"inputTypes,omitempty"`
+	// ResultTypes describe the rendered bodies, wrapped by Engine.Envelope
+	// when set.
 	ResultTypes []RouteManifestType `json:"resultTypes,omitempty"`
+	// Statuses are the success statuses of the route, from its result types
+	// implementing StatusCoder, 200 for other results, and Responds.
+	Statuses []int `json:"statuses,omitempty"`
+	// Paginated reports whether the route returns a Paginated result,
+	// rendered as its items with the Link and total count headers.
+	Paginated bool `json:"paginated,omitempty"`
+	// Parameters lists the handler parameters bound from the request with
+	// their source, for handlers taking BindingSourcer parameters.
+	Parameters []RouteManifestParameter `json:"parameters,omitempty"`
+	Meta       RouteMeta                `json:"meta,omitempty"`
+	// Security lists the requirements of the route in the shape of an
+	// OpenAPI security requirement: the authentication scheme mapped to the
+	// scopes required by its authorization policies. An empty requirement
+	// marks authentication as optional.
+	Security []map[string][]string `json:"security,omitempty"`
 }
 
 // RouteManifestType is a serializable subset of reflect.Type.
@@ -45,6 +65,14 @@ type RouteManifestType struct {
 	Fields  []RouteManifestField `json:"fields,omitempty"`
 }
 
+// RouteManifestParameter is a handler parameter bound from the request.
+type RouteManifestParameter struct {
+	// Source is the BindingSource of the parameter, or "request" for the
+	// parameter bound from all sources.
+	Source string            `json:"source"`
+	Type   RouteManifestType `json:"type"`
+}
+
 // RouteManifestField is a serializable subset of reflect.StructField.
 type RouteManifestField struct {
 	Name      string            `json:"name"`
@@ -61,7 +89,11 @@ func RouteManifestFromEngine(engine *Engine) RouteManifest {
 		return manifest
 	}
 	for _, route := range engine.HandlerRoutes() {
-		manifest.Routes = append(manifest.Routes, routeManifestRoute(route))
+		result := routeManifestRoute(route)
+		if engine.Envelope != nil && result.ResultTypes != nil {
+			result.ResultTypes = routeManifestEnvelopedResults(engine, route.HandlerType)
+		}
+		manifest.Routes = append(manifest.Routes, result)
 	}
 	return manifest
 }
@@ -87,26 +119,125 @@ func WriteRouteManifest(engine *Engine, path string) error {
 
 func routeManifestRoute(route RouteInfo) RouteManifestRoute {
 	result := RouteManifestRoute{
-		Method:  route.Method,
-		Path:    route.Path,
-		Handler: route.HandlerName,
+		Method:   route.Method,
+		Path:     route.Path,
+		Handler:  route.HandlerName,
+		Version:  route.Version,
+		Meta:     route.Meta,
+		Security: routeManifestSecurity(route.Meta),
+	}
+	if version, ok := route.Meta["version"].(*apiVersion); ok {
+		result.Deprecated = version.deprecation != nil
 	}
 	if route.HandlerType == nil {
 		return result
 	}
+	result.Parameters = routeManifestParameters(route.HandlerType, route.Services)
+	result.Statuses = routeStatuses(route)
+	if route.HandlerType.Kind() == reflect.Func {
+		for i := range route.HandlerType.NumOut() {
+			if route.HandlerType.Out(i).Implements(reflect.TypeFor[paginator]()) {
+				result.Paginated = true
+			}
+		}
+	}
 	if !routeManifestNeedsInlineTypes(route.HandlerName) {
 		return result
 	}
-	result.InputTypes = manifestTypeList(route.HandlerType, true, map[reflect.Type]bool{})
+	result.InputTypes = manifestTypeList(route.HandlerType, true, map[reflect.Type]bool{}, route.Services...)
 	result.ResultTypes = manifestTypeList(route.HandlerType, false, map[reflect.Type]bool{})
 	return result
 }
 
+// routeManifestEnvelopedResults lists the results of a handler type as
+// wrapped by the engine Envelope.
+func routeManifestEnvelopedResults(engine *Engine, typ reflect.Type) []RouteManifestType {
+	seen := map[reflect.Type]bool{}
+	result := make([]RouteManifestType, 0, typ.NumOut())
+	for i := range typ.NumOut() {
+		output := typ.Out(i)
+		if body, ok := responseBodyType(output); ok {
+			output = body
+		}
+		if !output.Implements(errorType) || engine.RenderErrorFunc == nil {
+			output = envelopedType(engine.Envelope, output)
+		}
+		result = append(result, routeManifestType(output, seen))
+	}
+	return result
+}
+
+// routeManifestSecurity derives the security requirements from the
+// "authentication" and "authorization" route metadata.
+func routeManifestSecurity(meta RouteMeta) []map[string][]string {
+	authentication, ok := meta["authentication"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	scheme, _ := authentication["scheme"].(string)
+	if scheme == "" {
+		return nil
+	}
+
+	scopes := []string{}
+	policies, _ := meta["authorization"].(RouteMetaList)
+	for _, policy := range policies {
+		if policy, ok := policy.(map[string]any); ok {
+			required, _ := policy["scopes"].([]string)
+			for _, scope := range required {
+				if !slices.Contains(scopes, scope) {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+
+	security := []map[string][]string{{scheme: scopes}}
+	if optional, _ := authentication["optional"].(bool); optional {
+		security = append(security, map[string][]string{})
+	}
+	return security
+}
+
+// routeManifestParameters lists the bound parameters of a handler type with
+// their source, or nil when none is a BindingSourcer.
+func routeManifestParameters(typ reflect.Type, services []reflect.Type) []RouteManifestParameter {
+	if typ.Kind() != reflect.Func {
+		return nil
+	}
+	var (
+		params  []RouteManifestParameter
+		sourced bool
+	)
+	for i := 0; i < typ.NumIn(); i++ {
+		input := typ.In(i)
+		if routeManifestIsFoxContext(input) || slices.Contains(services, input) {
+			continue
+		}
+		source := string(bindingSourceOf(input))
+		if source == "" {
+			source = "request"
+		} else {
+			sourced = true
+		}
+		params = append(params, RouteManifestParameter{
+			Source: source,
+			Type:   routeManifestType(input, map[reflect.Type]bool{}),
+		})
+	}
+	if !sourced {
+		return nil
+	}
+	return params
+}
+
 func routeManifestNeedsInlineTypes(handlerName string) bool {
 	return strings.Contains(handlerName, ".func")
 }
 
-func manifestTypeList(typ reflect.Type, inputs bool, seen map[reflect.Type]bool) []RouteManifestType {
+// manifestTypeList lists the inputs or results of a handler type, skipping
+// *Context, context.Context and the injected services.
+func manifestTypeList(typ reflect.Type, inputs bool, seen map[reflect.Type]bool, services ...reflect.Type) []RouteManifestType {
 	var count int
 	if inputs {
 		count = typ.NumIn()
@@ -117,12 +248,17 @@ func manifestTypeList(typ reflect.Type, inputs bool, seen map[reflect.Type]bool)
 	for i := 0; i < count; i++ {
 		if inputs {
 			input := typ.In(i)
-			if routeManifestIsFoxContext(input) {
+			if routeManifestIsFoxContext(input) || slices.Contains(services, input) {
 				continue
 			}
 			result = append(result, routeManifestType(input, seen))
 		} else {
-			result = append(result, routeManifestType(typ.Out(i), seen))
+			output := typ.Out(i)
+			// Describe the body of Response results.
+			if body, ok := responseBodyType(output); ok {
+				output = body
+			}
+			result = append(result, routeManifestType(output, seen))
 		}
 	}
 	return result
@@ -191,6 +327,9 @@ func routeManifestIsConcreteError(typ reflect.Type) bool {
 }
 
 func routeManifestIsFoxContext(typ reflect.Type) bool {
+	if typ == stdContextType {
+		return true
+	}
 	for typ.Kind() == reflect.Pointer {
 		typ = typ.Elem()
 	}
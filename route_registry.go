@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"runtime"
 	"sort"
+	"strings"
 )
 
 type handlerRouteKey struct {
@@ -20,9 +21,18 @@ type RouteInfo struct {
 	Handler     HandlerFunc
 	HandlerType reflect.Type
 	HandlerName string
+	// Services are the handler parameter types injected by Engine.Provide
+	// rather than bound from the request.
+	Services []reflect.Type
+	// Version is the API version of routes registered on a version group,
+	// see RouterGroup.Versioning.
+	Version string
+	// Meta is the metadata of the Middleware values attached to the route
+	// and its groups.
+	Meta RouteMeta
 }
 
-func (engine *Engine) registerHandlerRoute(method, path string, handlers HandlersChain) {
+func (engine *Engine) registerHandlerRoute(method, path string, handlers HandlersChain, meta RouteMeta) {
 	if engine.handlerRoutesDisabled.Load() {
 		return
 	}
@@ -51,12 +61,25 @@ func (engine *Engine) registerHandlerRoute(method, path string, handlers Handler
 		engine.handlerRoutes = make(map[handlerRouteKey]RouteInfo)
 	}
 
+	var services []reflect.Type
+	if handlerType := reflect.TypeOf(handler); handlerType.Kind() == reflect.Func {
+		for i := 1; i < handlerType.NumIn(); i++ {
+			if engine.services.provides(handlerType.In(i)) {
+				services = append(services, handlerType.In(i))
+			}
+		}
+	}
+
+	version, _ := meta["version"].(*apiVersion)
 	engine.handlerRoutes[handlerRouteKey{Method: method, Path: path}] = RouteInfo{
 		Method:      method,
 		Path:        path,
 		Handler:     handler,
 		HandlerType: reflect.TypeOf(handler),
 		HandlerName: funcName,
+		Services:    services,
+		Version:     version.String(),
+		Meta:        meta,
 	}
 }
 
@@ -79,3 +102,37 @@ func (engine *Engine) HandlerRoutes() []RouteInfo {
 
 	return routes
 }
+
+// routePathMatches reports whether path matches a gin route template, where
+// ":name" matches one segment and "*name" the rest of the path.
+func routePathMatches(template, path string) bool {
+	for template != "" {
+		switch template[0] {
+		case '*':
+			return true
+		case ':':
+			end := strings.IndexByte(template, '/')
+			segment := strings.IndexByte(path, '/')
+			if segment == 0 {
+				return false
+			}
+			if end < 0 {
+				return segment < 0 && path != ""
+			}
+			if segment < 0 {
+				return false
+			}
+			template, path = template[end:], path[segment:]
+		default:
+			i := strings.IndexAny(template, ":*")
+			if i < 0 {
+				return template == path
+			}
+			if !strings.HasPrefix(path, template[:i]) {
+				return false
+			}
+			template, path = template[i:], path[i:]
+		}
+	}
+	return path == ""
+}
@@ -102,7 +102,7 @@ func TestHandlerRoutesAreSortedAndCanBeDisabled(t *testing.T) {
 
 func TestRegisterHandlerRouteIgnoresEmptyHandlerChain(t *testing.T) {
 	engine := New()
-	engine.registerHandlerRoute("GET", "/empty", nil)
+	engine.registerHandlerRoute("GET", "/empty", nil, nil)
 	require.Empty(t, engine.HandlerRoutes())
 }
 
@@ -285,6 +285,6 @@ func BenchmarkRegisterHandlerRoute_Disabled(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		engine.registerHandlerRoute("GET", "/x", handlers)
+		engine.registerHandlerRoute("GET", "/x", handlers, nil)
 	}
 }
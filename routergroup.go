@@ -20,29 +20,55 @@ var anyMethods = []string{
 
 // RouterGroup is gin.RouterGroup wrapper.
 type RouterGroup struct {
-	router *gin.RouterGroup
-	engine *Engine
+	router  *gin.RouterGroup
+	engine  *Engine
+	meta    RouteMeta
+	version *apiVersion
 }
 
 // handleWrapper gin.Handle wrapper.
 func (group *RouterGroup) handleWrapper(handlers ...HandlerFunc) gin.HandlersChain {
+	return group.wrapHandlers(handlers, false)
+}
+
+// wrapHandlers converts handlers to gin handlers. With route, the last handler
+// is the route handler, which rejects request bodies over the limit once every
+// BodyLimit of the route has run.
+func (group *RouterGroup) wrapHandlers(handlers HandlersChain, route bool) gin.HandlersChain {
 	var handlersChain gin.HandlersChain
 
-	for _, handler := range handlers {
-		if !IsValidHandlerFunc(handler) {
+	for i, handler := range handlers {
+		if !isValidHandlerFunc(handler, group.engine.services) {
 			panic(fmt.Sprintf(MsgInvalidHandlerType, reflect.TypeOf(handler).String(), utils.NameOfFunction(handler)))
 		}
+		group.engine.recordBoundParams(handler)
 
-		f := func(h HandlerFunc) gin.HandlerFunc {
+		f := func(h HandlerFunc, last bool) gin.HandlerFunc {
 			// support use gin middleware
 			if ginHandler, ok := h.(gin.HandlerFunc); ok {
 				return ginHandler
 			}
+			if m, ok := h.(*Middleware); ok {
+				return m.Handler
+			}
+
+			handlerName := utils.NameOfFunction(h)
 
 			return func(c *gin.Context) {
+				// The first handler of the request owns its services and runs
+				// the rest of the chain before cleaning them up.
+				services := startServiceScope(c, group.engine)
+				if services != nil {
+					defer services.close()
+				}
+
 				xRequestID := c.Writer.Header().Get(logger.TraceID)
 				if xRequestID == "" {
-					xRequestID = logger.DefaultGenRequestID()
+					// Keep the request ID of the client or of the Tracing
+					// middleware, as the Logger middleware does.
+					if xRequestID = c.GetHeader(logger.TraceID); xRequestID == "" {
+						xRequestID = logger.DefaultGenRequestID()
+					}
 					c.Header(logger.TraceID, xRequestID)
 				}
 
@@ -55,28 +81,51 @@ func (group *RouterGroup) handleWrapper(handlers ...HandlerFunc) gin.HandlersCha
 					log = logger.New(xRequestID)
 				}
 
-				var (
-					ctx = &Context{
-						Context: c,
-						engine:  group.engine,
-						Logger:  log,
-						Request: c.Request,
-					}
+				endSpan := startHandlerSpan(c, handlerName)
+
+				ctx := &Context{
+					Context: c,
+					engine:  group.engine,
+					Logger:  log,
+					Request: c.Request,
+					timing:  serverTimingFromContext(c),
+				}
+
+				var err error
+				if last {
+					err = checkBodyLimit(c, group.engine)
+				} else {
+					limitBody(c, group.engine)
+				}
+				var res any
+				if err != nil {
+					res = err
+				} else {
 					res = call(ctx, h)
-				)
+				}
 				// The Context.Request may be changed in middleware,
 				// so we need to update the gin.Context.Request at here
 				c.Request = ctx.Request
 
+				if endSpan != nil {
+					endSpan(c, res)
+				}
+
 				if ctx.IsAborted() {
 					return
 				}
 
+				ctx.timing.beginRender()
 				ctx.render(res)
+				ctx.timing.endRender()
+
+				if services != nil {
+					c.Next()
+				}
 			}
 		}
 
-		handlersChain = append(handlersChain, f(handler))
+		handlersChain = append(handlersChain, f(handler, route && i == len(handlers)-1))
 	}
 
 	// GIN handle
@@ -88,6 +137,7 @@ func (group *RouterGroup) handleWrapper(handlers ...HandlerFunc) gin.HandlersCha
 // Use adds middleware to the group, see example code in GitHub.
 func (group *RouterGroup) Use(middleware ...HandlerFunc) gin.IRoutes {
 	handlersChain := group.handleWrapper(middleware...)
+	group.meta = mergeRouteMeta(group.meta, middleware)
 	return group.router.Use(handlersChain...)
 }
 
@@ -96,19 +146,29 @@ func (group *RouterGroup) Use(middleware ...HandlerFunc) gin.IRoutes {
 func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
 	handlersChain := group.handleWrapper(handlers...)
 	return &RouterGroup{
-		router: group.router.Group(relativePath, handlersChain...),
-		engine: group.engine,
+		router:  group.router.Group(relativePath, handlersChain...),
+		engine:  group.engine,
+		meta:    mergeRouteMeta(group.meta, handlers),
+		version: group.version,
 	}
 }
 
 // Handle gin.Handle wrapper.
 func (group *RouterGroup) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) gin.IRoutes {
-	handlersChain := group.handleWrapper(handlers...)
+	handlersChain := group.wrapHandlers(handlers, true)
 
 	absolutePath := utils.JoinPaths(group.router.BasePath(), relativePath)
 	debugPrintRoute(group, httpMethod, absolutePath, handlers)
-	group.engine.registerHandlerRoute(httpMethod, absolutePath, handlers)
-	return group.router.Handle(httpMethod, relativePath, handlersChain...)
+	group.engine.checkVersionedRoute(httpMethod, absolutePath)
+	meta := mergeRouteMeta(group.meta, handlers)
+	group.engine.registerHandlerRoute(httpMethod, absolutePath, handlers, meta)
+	group.engine.registerCORSRoute(httpMethod, absolutePath, meta)
+	group.engine.registerRouteMethod(httpMethod, absolutePath)
+	routes := group.router.Handle(httpMethod, relativePath, handlersChain...)
+	if group.version != nil {
+		group.version.share(httpMethod, absolutePath, handlers, meta)
+	}
+	return routes
 }
 
 // GET is a shortcut for router.Handle("GET", path, handle).
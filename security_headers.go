@@ -0,0 +1,395 @@
+package fox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cspNonceContextKey stores the CSP nonce of the current request.
+const cspNonceContextKey = "_fox-gonic/fox/csp-nonce"
+
+// maxCSPReportSize bounds the body of violation reports.
+const maxCSPReportSize = 64 << 10
+
+// CSP source expressions.
+const (
+	CSPSelf           = "'self'"
+	CSPNone           = "'none'"
+	CSPUnsafeInline   = "'unsafe-inline'"
+	CSPUnsafeEval     = "'unsafe-eval'"
+	CSPStrictDynamic  = "'strict-dynamic'"
+	CSPReportSample   = "'report-sample'"
+	CSPUnsafeHashes   = "'unsafe-hashes'"
+	CSPWasmUnsafeEval = "'wasm-unsafe-eval'"
+
+	// CSPNonce is replaced by a 'nonce-...' source with a nonce generated for
+	// every request, see Context.CSPNonce.
+	CSPNonce = "'nonce'"
+)
+
+// CSP builds a Content-Security-Policy.
+//
+//	csp := fox.NewCSP().
+//		Directive("default-src", fox.CSPSelf).
+//		Directive("script-src", fox.CSPNonce, fox.CSPStrictDynamic).
+//		Directive("object-src", fox.CSPNone).
+//		Directive("report-uri", "/csp-report")
+type CSP struct {
+	directives []cspDirective
+}
+
+type cspDirective struct {
+	name    string
+	sources []string
+}
+
+// NewCSP returns an empty policy.
+func NewCSP() *CSP {
+	return &CSP{}
+}
+
+// Directive adds sources to a directive, creating it if needed. Directives
+// without sources, such as upgrade-insecure-requests, are added as is.
+func (p *CSP) Directive(name string, sources ...string) *CSP {
+	name = strings.ToLower(name)
+	for i := range p.directives {
+		if p.directives[i].name == name {
+			p.directives[i].sources = append(p.directives[i].sources, sources...)
+			return p
+		}
+	}
+	p.directives = append(p.directives, cspDirective{name: name, sources: sources})
+	return p
+}
+
+// Has reports whether the policy has the directive.
+func (p *CSP) Has(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(p.directives, func(d cspDirective) bool { return d.name == name })
+}
+
+// String returns the policy with CSPNonce sources left as is.
+func (p *CSP) String() string {
+	return p.build("")
+}
+
+func (p *CSP) clone() *CSP {
+	directives := make([]cspDirective, len(p.directives))
+	for i, d := range p.directives {
+		directives[i] = cspDirective{name: d.name, sources: slices.Clone(d.sources)}
+	}
+	return &CSP{directives: directives}
+}
+
+// usesNonce reports whether the policy has CSPNonce sources.
+func (p *CSP) usesNonce() bool {
+	return slices.ContainsFunc(p.directives, func(d cspDirective) bool {
+		return slices.Contains(d.sources, CSPNonce)
+	})
+}
+
+func (p *CSP) build(nonce string) string {
+	var b strings.Builder
+	for i, d := range p.directives {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(d.name)
+		for _, source := range d.sources {
+			if source == CSPNonce && nonce != "" {
+				source = "'nonce-" + nonce + "'"
+			}
+			b.WriteByte(' ')
+			b.WriteString(source)
+		}
+	}
+	return b.String()
+}
+
+// SecurityHeadersConfig defines the config for SecurityHeaders middleware.
+// Empty fields are not sent.
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge sends Strict-Transport-Security on HTTPS requests, including
+	// requests with X-Forwarded-Proto https.
+	// Optional.
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains adds includeSubDomains to HSTS.
+	// Optional.
+	HSTSIncludeSubdomains bool
+
+	// HSTSPreload adds preload to HSTS.
+	// Optional.
+	HSTSPreload bool
+
+	// ContentTypeNosniff sends X-Content-Type-Options: nosniff.
+	// Optional.
+	ContentTypeNosniff bool
+
+	// FrameOptions is the X-Frame-Options value, DENY or SAMEORIGIN. A CSP
+	// without frame-ancestors gets the equivalent directive.
+	// Optional.
+	FrameOptions string
+
+	// ReferrerPolicy is the Referrer-Policy value.
+	// Optional.
+	ReferrerPolicy string
+
+	// PermissionsPolicy is the Permissions-Policy value, for example
+	// "camera=(), microphone=(), geolocation=()".
+	// Optional.
+	PermissionsPolicy string
+
+	// CrossOriginOpenerPolicy is the Cross-Origin-Opener-Policy value.
+	// Optional.
+	CrossOriginOpenerPolicy string
+
+	// CrossOriginEmbedderPolicy is the Cross-Origin-Embedder-Policy value.
+	// Optional.
+	CrossOriginEmbedderPolicy string
+
+	// CrossOriginResourcePolicy is the Cross-Origin-Resource-Policy value.
+	// Optional.
+	CrossOriginResourcePolicy string
+
+	// CSP is the Content-Security-Policy.
+	// Optional.
+	CSP *CSP
+
+	// CSPReportOnly sends the CSP as Content-Security-Policy-Report-Only, so
+	// that violations are reported but not enforced.
+	// Optional.
+	CSPReportOnly bool
+}
+
+// DefaultSecurityHeadersConfig returns the config used by SecurityHeaders
+// without arguments: nosniff, X-Frame-Options DENY, a strict-origin
+// Referrer-Policy, same-origin COOP and CORP, and one year of HSTS.
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:                365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:     true,
+		ContentTypeNosniff:        true,
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginResourcePolicy: "same-origin",
+	}
+}
+
+// SecurityHeaders returns a middleware setting browser security headers on
+// every response, DefaultSecurityHeadersConfig when called without config.
+// Handlers can still override them.
+//
+// A CSP with CSPNonce sources gets a fresh nonce for every request, available
+// from Context.CSPNonce and as the "cspNonce" value of render.HTML data:
+//
+//	<script nonce="{{.cspNonce}}">...</script>
+//
+// The headers are recorded as the "securityHeaders" route metadata.
+func SecurityHeaders(config ...SecurityHeadersConfig) *Middleware {
+	conf := DefaultSecurityHeadersConfig()
+	if len(config) > 0 {
+		conf = config[0]
+	}
+
+	headers := http.Header{}
+	if conf.ContentTypeNosniff {
+		headers.Set("X-Content-Type-Options", "nosniff")
+	}
+	if conf.FrameOptions != "" {
+		headers.Set("X-Frame-Options", conf.FrameOptions)
+	}
+	if conf.ReferrerPolicy != "" {
+		headers.Set("Referrer-Policy", conf.ReferrerPolicy)
+	}
+	if conf.PermissionsPolicy != "" {
+		headers.Set("Permissions-Policy", conf.PermissionsPolicy)
+	}
+	if conf.CrossOriginOpenerPolicy != "" {
+		headers.Set("Cross-Origin-Opener-Policy", conf.CrossOriginOpenerPolicy)
+	}
+	if conf.CrossOriginEmbedderPolicy != "" {
+		headers.Set("Cross-Origin-Embedder-Policy", conf.CrossOriginEmbedderPolicy)
+	}
+	if conf.CrossOriginResourcePolicy != "" {
+		headers.Set("Cross-Origin-Resource-Policy", conf.CrossOriginResourcePolicy)
+	}
+
+	var hsts string
+	if conf.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(conf.HSTSMaxAge/time.Second), 10)
+		if conf.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if conf.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	var csp *CSP
+	if conf.CSP != nil {
+		csp = conf.CSP.clone()
+	}
+	cspHeader := "Content-Security-Policy"
+	if conf.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	if csp != nil && !csp.Has("frame-ancestors") {
+		switch strings.ToUpper(conf.FrameOptions) {
+		case "DENY":
+			csp.Directive("frame-ancestors", CSPNone)
+		case "SAMEORIGIN":
+			csp.Directive("frame-ancestors", CSPSelf)
+		}
+	}
+
+	meta := map[string]any{}
+	for name := range headers {
+		meta[name] = headers.Get(name)
+	}
+	if hsts != "" {
+		meta["Strict-Transport-Security"] = hsts
+	}
+	if csp != nil {
+		meta[cspHeader] = csp.String()
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			header := c.Writer.Header()
+			for name := range headers {
+				header.Set(name, headers.Get(name))
+			}
+			if hsts != "" && requestIsHTTPS(c.Request) {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+			if csp == nil {
+				return
+			}
+			var nonce string
+			if csp.usesNonce() {
+				nonce = newCSPNonce()
+				c.Set(cspNonceContextKey, nonce)
+				SetTemplateData(c, "cspNonce", nonce)
+			}
+			header.Set(cspHeader, csp.build(nonce))
+		},
+		Meta: RouteMeta{"securityHeaders": meta},
+	}
+}
+
+// CSPNonce returns the Content-Security-Policy nonce of the request, or "" when
+// the policy of SecurityHeaders has no CSPNonce source.
+func (c *Context) CSPNonce() string {
+	return c.GetString(cspNonceContextKey)
+}
+
+// newCSPNonce returns a base64url nonce, which templates embed without
+// escaping.
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func requestIsHTTPS(req *http.Request) bool {
+	return req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// CSPReportHandler returns a handler collecting Content-Security-Policy
+// violation reports, in the report-uri (application/csp-report) and
+// Reporting API (application/reports+json) formats, and logging them as
+// warnings through the request logger.
+//
+//	router.POST("/csp-report", fox.CSPReportHandler())
+func CSPReportHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCSPReportSize))
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		reports, ok := parseCSPReports(body)
+		if !ok {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		log := requestLogger(c)
+		for _, report := range reports {
+			report["client_ip"] = c.ClientIP()
+			report["user_agent"] = c.Request.UserAgent()
+			log.WithFields(report).Warn("content security policy violation")
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// parseCSPReports returns the violations of a report-uri or Reporting API
+// payload with normalized field names.
+func parseCSPReports(body []byte) ([]map[string]any, bool) {
+	var legacy struct {
+		Report map[string]any `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err == nil && legacy.Report != nil {
+		return []map[string]any{normalizeCSPReport(legacy.Report)}, true
+	}
+
+	var reports []struct {
+		Type string         `json:"type"`
+		Body map[string]any `json:"body"`
+	}
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, false
+	}
+	var violations []map[string]any
+	for _, report := range reports {
+		if report.Type == "csp-violation" && report.Body != nil {
+			violations = append(violations, normalizeCSPReport(report.Body))
+		}
+	}
+	return violations, true
+}
+
+// cspReportFields maps report-uri and Reporting API field names to log
+// fields.
+var cspReportFields = map[string]string{
+	"document-uri":        "document_uri",
+	"documentURL":         "document_uri",
+	"blocked-uri":         "blocked_uri",
+	"blockedURL":          "blocked_uri",
+	"violated-directive":  "effective_directive",
+	"effective-directive": "effective_directive",
+	"effectiveDirective":  "effective_directive",
+	"original-policy":     "original_policy",
+	"originalPolicy":      "original_policy",
+	"disposition":         "disposition",
+	"source-file":         "source_file",
+	"sourceFile":          "source_file",
+	"line-number":         "line_number",
+	"lineNumber":          "line_number",
+	"script-sample":       "sample",
+	"sample":              "sample",
+}
+
+func normalizeCSPReport(report map[string]any) map[string]any {
+	fields := map[string]any{}
+	for key, value := range report {
+		if name, ok := cspReportFields[key]; ok {
+			if _, exists := fields[name]; !exists || key != "violated-directive" {
+				fields[name] = value
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,167 @@
+package fox
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/logger"
+	"github.com/fox-gonic/fox/render"
+)
+
+// recordingLogger records the fields and messages of warnings.
+type recordingLogger struct {
+	logger.Logger
+
+	fields   []map[string]any
+	messages []any
+}
+
+func (l *recordingLogger) WithFields(fields map[string]any) logger.Logger {
+	l.fields = append(l.fields, fields)
+	return l
+}
+
+func (l *recordingLogger) Warn(arguments ...any) {
+	l.messages = append(l.messages, arguments...)
+}
+
+func TestSecurityHeaders_Default(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(SecurityHeaders())
+	router.GET("/", func() string { return "ok" })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "same-origin", w.Header().Get("Cross-Origin-Opener-Policy"))
+	assert.Equal(t, "same-origin", w.Header().Get("Cross-Origin-Resource-Policy"))
+	assert.Empty(t, w.Header().Get("Cross-Origin-Embedder-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"), "HSTS is only sent over HTTPS")
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeaders_CSPNonce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	csp := NewCSP().
+		Directive("default-src", CSPSelf).
+		Directive("script-src", CSPNonce, CSPStrictDynamic).
+		Directive("upgrade-insecure-requests")
+	tmpl := template.Must(template.New("page").Parse(`<script nonce="{{.cspNonce}}"></script>`))
+
+	var nonce string
+	router := New()
+	router.Use(SecurityHeaders(SecurityHeadersConfig{
+		FrameOptions:      "SAMEORIGIN",
+		PermissionsPolicy: "camera=()",
+		CSP:               csp,
+	}))
+	router.GET("/", func(c *Context) render.HTML {
+		nonce = c.CSPNonce()
+		return render.HTML{Template: tmpl, Name: "page"}
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEmpty(t, nonce)
+	assert.Equal(t,
+		"default-src 'self'; script-src 'nonce-"+nonce+"' 'strict-dynamic'; upgrade-insecure-requests; frame-ancestors 'self'",
+		w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "camera=()", w.Header().Get("Permissions-Policy"))
+	assert.Equal(t, `<script nonce="`+nonce+`"></script>`, w.Body.String())
+	assert.False(t, csp.Has("frame-ancestors"), "the caller's policy is not changed")
+
+	first := nonce
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEqual(t, first, nonce)
+
+	route := router.HandlerRoutes()[0]
+	headers := route.Meta["securityHeaders"].(map[string]any)
+	assert.Equal(t, "default-src 'self'; script-src 'nonce' 'strict-dynamic'; upgrade-insecure-requests; frame-ancestors 'self'",
+		headers["Content-Security-Policy"])
+}
+
+func TestSecurityHeaders_ReportOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/", SecurityHeaders(SecurityHeadersConfig{
+		CSP:           NewCSP().Directive("default-src", CSPSelf).Directive("report-uri", "/csp-report"),
+		CSPReportOnly: true,
+	}), func(c *Context) string { return c.CSPNonce() })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "default-src 'self'; report-uri /csp-report", w.Header().Get("Content-Security-Policy-Report-Only"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := &recordingLogger{}
+	router := New()
+	router.POST("/csp-report", gin.HandlerFunc(func(c *gin.Context) {
+		c.Set(LoggerContextKey, logger.Logger(log))
+	}), CSPReportHandler())
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		fields      map[string]any
+	}{
+		{
+			"report-uri", "application/csp-report",
+			`{"csp-report":{"document-uri":"https://example.com/","blocked-uri":"https://evil.test/x.js","violated-directive":"script-src","effective-directive":"script-src-elem","line-number":3}}`,
+			http.StatusNoContent,
+			map[string]any{"document_uri": "https://example.com/", "blocked_uri": "https://evil.test/x.js", "effective_directive": "script-src-elem", "line_number": float64(3)},
+		},
+		{
+			"reporting api", "application/reports+json",
+			`[{"type":"csp-violation","body":{"documentURL":"https://example.com/","blockedURL":"inline","effectiveDirective":"style-src-attr","disposition":"report"}},{"type":"deprecation","body":{}}]`,
+			http.StatusNoContent,
+			map[string]any{"document_uri": "https://example.com/", "blocked_uri": "inline", "effective_directive": "style-src-attr", "disposition": "report"},
+		},
+		{"malformed", "application/csp-report", `nope`, http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log.fields, log.messages = nil, nil
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.fields == nil {
+				assert.Empty(t, log.fields)
+				return
+			}
+			require.Len(t, log.fields, 1)
+			for key, value := range tt.fields {
+				assert.Equal(t, value, log.fields[0][key], key)
+			}
+			assert.Equal(t, []any{"content security policy violation"}, log.messages)
+		})
+	}
+}
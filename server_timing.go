@@ -0,0 +1,258 @@
+package fox
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const headerServerTiming = "Server-Timing"
+
+// serverTimingContextKey stores the per-request timings in the gin context.
+const serverTimingContextKey = "_fox-gonic/fox/server-timing"
+
+// Phases recorded by fox for every handler in the chain. Timings of the same
+// name are summed.
+const (
+	ServerTimingBind    = "bind"
+	ServerTimingHandler = "handler"
+	ServerTimingRender  = "render"
+	ServerTimingTotal   = "total"
+)
+
+// ServerTimingConfig defines the config for ServerTiming middleware.
+type ServerTimingConfig struct {
+	// LogFields adds the timings to the Logger access log as the
+	// "server_timing" field, in milliseconds.
+	// Optional.
+	LogFields bool
+}
+
+// ServerTimingMetric is a single Server-Timing entry.
+type ServerTimingMetric struct {
+	Name        string
+	Duration    time.Duration
+	Description string
+}
+
+type serverTiming struct {
+	config ServerTimingConfig
+	start  time.Time
+
+	mu          sync.Mutex
+	metrics     []ServerTimingMetric
+	renderStart time.Time
+	emitted     bool
+}
+
+// add records a timing, summing it with an existing one of the same name.
+// It is a no-op on a nil receiver so call sites need not check whether the
+// middleware is installed.
+func (t *serverTiming) add(name string, duration time.Duration, description string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.metrics {
+		if t.metrics[i].Name == name {
+			t.metrics[i].Duration += duration
+			if description != "" {
+				t.metrics[i].Description = description
+			}
+			return
+		}
+	}
+	t.metrics = append(t.metrics, ServerTimingMetric{Name: name, Duration: duration, Description: description})
+}
+
+func (t *serverTiming) since(name string, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.add(name, time.Since(start), "")
+}
+
+func (t *serverTiming) beginRender() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.renderStart = time.Now()
+}
+
+func (t *serverTiming) endRender() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	start := t.renderStart
+	t.renderStart = time.Time{}
+	t.mu.Unlock()
+	if !start.IsZero() {
+		t.since(ServerTimingRender, start)
+	}
+}
+
+// snapshot returns the recorded metrics, including the time spent so far in
+// an unfinished render and the total time since the request started.
+func (t *serverTiming) snapshot() []ServerTimingMetric {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := make([]ServerTimingMetric, 0, len(t.metrics)+2)
+	metrics = append(metrics, t.metrics...)
+	if !t.renderStart.IsZero() {
+		elapsed := time.Since(t.renderStart)
+		found := false
+		for i := range metrics {
+			if metrics[i].Name == ServerTimingRender {
+				metrics[i].Duration += elapsed
+				found = true
+			}
+		}
+		if !found {
+			metrics = append(metrics, ServerTimingMetric{Name: ServerTimingRender, Duration: elapsed})
+		}
+	}
+	return append(metrics, ServerTimingMetric{Name: ServerTimingTotal, Duration: time.Since(t.start)})
+}
+
+// emit sets the Server-Timing header once, right before the response header
+// is written.
+func (t *serverTiming) emit(w gin.ResponseWriter) {
+	t.mu.Lock()
+	if t.emitted {
+		t.mu.Unlock()
+		return
+	}
+	t.emitted = true
+	t.mu.Unlock()
+
+	w.Header().Set(headerServerTiming, formatServerTiming(t.snapshot()))
+}
+
+func formatServerTiming(metrics []ServerTimingMetric) string {
+	var b strings.Builder
+	for i, metric := range metrics {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(metric.Name)
+		if metric.Description != "" {
+			b.WriteString(`;desc=`)
+			b.WriteString(strconv.Quote(metric.Description))
+		}
+		b.WriteString(";dur=")
+		b.WriteString(formatMilliseconds(metric.Duration))
+	}
+	return b.String()
+}
+
+func formatMilliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d.Microseconds())/1000, 'f', -1, 64)
+}
+
+func serverTimingFromContext(c *gin.Context) *serverTiming {
+	if v, exists := c.Get(serverTimingContextKey); exists {
+		if t, ok := v.(*serverTiming); ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// serverTimingWriter sets the Server-Timing header when the response is
+// committed, so that the time spent rendering the body is included.
+type serverTimingWriter struct {
+	gin.ResponseWriter
+	timing *serverTiming
+}
+
+// Write implement http.ResponseWriter
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	if !w.Written() {
+		w.timing.emit(w.ResponseWriter)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString implement io.StringWriter
+func (w *serverTimingWriter) WriteString(s string) (int, error) {
+	if !w.Written() {
+		w.timing.emit(w.ResponseWriter)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// WriteHeaderNow implement gin.ResponseWriter
+func (w *serverTimingWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.timing.emit(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush implement http.Flusher
+func (w *serverTimingWriter) Flush() {
+	if !w.Written() {
+		w.timing.emit(w.ResponseWriter)
+	}
+	w.ResponseWriter.Flush()
+}
+
+// ServerTiming middleware emits a Server-Timing header with the bind, handler
+// and render phases of the fox handlers in the chain, the total time, and any
+// timing added with Context.AddServerTiming.
+func ServerTiming(config ...ServerTimingConfig) gin.HandlerFunc {
+	var conf ServerTimingConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+
+	return func(c *gin.Context) {
+		timing := &serverTiming{config: conf, start: time.Now()}
+		c.Set(serverTimingContextKey, timing)
+		c.Writer = &serverTimingWriter{ResponseWriter: c.Writer, timing: timing}
+
+		c.Next()
+
+		// Responses without a body are committed by gin after the chain
+		// returns, bypassing the writer wrapper.
+		if !c.Writer.Written() {
+			timing.emit(c.Writer)
+		}
+	}
+}
+
+// serverTimingLogFields returns the timings as log fields when the
+// ServerTiming middleware asked for them.
+func serverTimingLogFields(c *gin.Context) map[string]any {
+	timing := serverTimingFromContext(c)
+	if timing == nil || !timing.config.LogFields {
+		return nil
+	}
+	metrics := timing.snapshot()
+	fields := make(map[string]any, len(metrics))
+	for _, metric := range metrics {
+		fields[metric.Name] = float64(metric.Duration.Microseconds()) / 1000
+	}
+	return fields
+}
+
+// AddServerTiming adds a custom entry to the Server-Timing header, for
+// example c.AddServerTiming("db", 12300*time.Microsecond) for "db;dur=12.3".
+// Entries of the same name are summed. It is a no-op unless the ServerTiming
+// middleware is installed, and entries added after the response has been
+// written are only reported in the log fields.
+func (c *Context) AddServerTiming(name string, duration time.Duration, description ...string) {
+	var desc string
+	if len(description) > 0 {
+		desc = description[0]
+	}
+	c.timing.add(name, duration, desc)
+}
@@ -0,0 +1,151 @@
+package fox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseServerTiming(header string) map[string]string {
+	metrics := make(map[string]string)
+	for _, entry := range strings.Split(header, ", ") {
+		name, params, _ := strings.Cut(entry, ";")
+		metrics[name] = params
+	}
+	return metrics
+}
+
+type serverTimingArgs struct {
+	Name string `json:"name"`
+}
+
+func TestServerTiming_Phases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(ServerTiming())
+	router.POST("/users", func(c *Context, args serverTimingArgs) (map[string]string, error) {
+		c.AddServerTiming("db", 12300*time.Microsecond, "primary")
+		c.AddServerTiming("cache", time.Millisecond)
+		c.AddServerTiming("cache", time.Millisecond)
+		return map[string]string{"name": args.Name}, nil
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"fox"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	header := w.Header().Get(headerServerTiming)
+	require.NotEmpty(t, header)
+
+	metrics := parseServerTiming(header)
+	for _, name := range []string{ServerTimingBind, ServerTimingHandler, ServerTimingRender, ServerTimingTotal} {
+		assert.Contains(t, metrics, name)
+		assert.True(t, strings.HasPrefix(metrics[name], "dur="), metrics[name])
+	}
+	assert.Equal(t, `desc="primary";dur=12.3`, metrics["db"])
+	assert.Equal(t, "dur=2", metrics["cache"])
+}
+
+func TestServerTiming_NoBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(ServerTiming())
+	router.GET("/empty", func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/empty", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	metrics := parseServerTiming(w.Header().Get(headerServerTiming))
+	assert.Contains(t, metrics, ServerTimingHandler)
+	assert.Contains(t, metrics, ServerTimingTotal)
+	assert.NotContains(t, metrics, ServerTimingBind)
+}
+
+func TestServerTiming_WithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/ping", func(c *Context) string {
+		c.AddServerTiming("db", time.Millisecond)
+		return "pong"
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(headerServerTiming))
+}
+
+func TestServerTiming_LogFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Nil(t, serverTimingLogFields(ginCtx))
+
+	timing := &serverTiming{config: ServerTimingConfig{LogFields: true}, start: time.Now()}
+	timing.add("db", 1500*time.Microsecond, "")
+	ginCtx.Set(serverTimingContextKey, timing)
+
+	fields := serverTimingLogFields(ginCtx)
+	assert.InDelta(t, 1.5, fields["db"], 0.0001)
+	assert.Contains(t, fields, ServerTimingTotal)
+
+	timing.config.LogFields = false
+	assert.Nil(t, serverTimingLogFields(ginCtx))
+}
+
+func TestFormatServerTiming(t *testing.T) {
+	header := formatServerTiming([]ServerTimingMetric{
+		{Name: "db", Duration: 12300 * time.Microsecond},
+		{Name: "cache", Duration: 250 * time.Microsecond, Description: `hit "warm"`},
+	})
+	assert.Equal(t, `db;dur=12.3, cache;desc="hit \"warm\"";dur=0.25`, header)
+}
+
+func TestServerTiming_MiddlewareCallingNext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var metrics []ServerTimingMetric
+	router := New()
+	router.Use(ServerTiming(), gin.HandlerFunc(func(c *gin.Context) {
+		c.Next()
+		metrics = serverTimingFromContext(c).snapshot()
+	}))
+	router.Use(func(c *Context) {
+		c.Next()
+	})
+	router.GET("/slow", func(c *Context) string {
+		time.Sleep(50 * time.Millisecond)
+		return "ok"
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var handler time.Duration
+	for _, metric := range metrics {
+		if metric.Name == ServerTimingHandler {
+			handler = metric.Duration
+		}
+	}
+	assert.GreaterOrEqual(t, handler, 50*time.Millisecond)
+	assert.Less(t, handler, 100*time.Millisecond, "the time of the handler is counted once")
+}
@@ -0,0 +1,346 @@
+package fox
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"maps"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultSessionCookie is the default SessionConfig.CookieName.
+const DefaultSessionCookie = "fox_session"
+
+// sessionContextKey stores the *Session of the current request.
+const sessionContextKey = "_fox-gonic/fox/session"
+
+// flashesSessionKey stores the flash messages in the session values.
+const flashesSessionKey = "_flashes"
+
+// SessionConfig defines the config for Sessions middleware.
+type SessionConfig struct {
+	// Store keeps the sessions, for example NewMemorySessionStore or
+	// NewCookieSessionStore. Required.
+	Store SessionStore
+
+	// CookieName is the session cookie name, default is "fox_session".
+	// Optional.
+	CookieName string
+
+	// Path of the session cookie, default is "/".
+	// Optional.
+	Path string
+
+	// Domain of the session cookie.
+	// Optional.
+	Domain string
+
+	// Secure restricts the session cookie to HTTPS.
+	// Optional.
+	Secure bool
+
+	// SameSite of the session cookie, default is http.SameSiteLaxMode.
+	// Optional.
+	SameSite http.SameSite
+
+	// IdleTimeout ends sessions unused for this long, default is 30m.
+	// Optional.
+	IdleTimeout time.Duration
+
+	// AbsoluteTimeout ends sessions this long after they started, whatever
+	// their use, default is 24h.
+	// Optional.
+	AbsoluteTimeout time.Duration
+}
+
+// Session is the server-side state of a client across requests, available
+// from Context.Session. Changes are saved when the response is written.
+type Session struct {
+	record SessionRecord
+
+	// token is the cookie value the session was loaded from.
+	token string
+	// stale is a token to delete, after Regenerate, Destroy or a timeout.
+	stale        string
+	isNew        bool
+	changed      bool
+	expireCookie bool
+}
+
+// Sessions returns a middleware that loads the session of the request from
+// the session cookie and saves it, refreshing the cookie, before the response
+// is written. A new session is only stored once a value is set. The cookie
+// name is recorded as the "session" route metadata.
+//
+//	router.Use(fox.Sessions(fox.SessionConfig{
+//		Store:  fox.NewCookieSessionStore(fox.CookieSessionStoreConfig{Keys: keys}),
+//		Secure: true,
+//	}))
+//	router.POST("/login", func(c *fox.Context, in *Login) error {
+//		...
+//		session := c.Session()
+//		session.Regenerate()
+//		session.Set("user_id", user.ID)
+//		return nil
+//	})
+//
+// Handlers receive session values through a `session:"key"` tag:
+//
+//	type Profile struct {
+//		UserID int64 `session:"user_id" json:"-"`
+//	}
+func Sessions(config SessionConfig) *Middleware {
+	if config.Store == nil {
+		panic("fox: SessionConfig.Store is required")
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultSessionCookie
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = 24 * time.Hour
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			session := loadSession(c, config)
+			c.Set(sessionContextKey, session)
+
+			w := &sessionWriter{ResponseWriter: c.Writer}
+			w.save = func() { saveSession(c, config, session) }
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+			w.commit()
+		},
+		Meta: RouteMeta{"session": map[string]any{"cookie": config.CookieName}},
+	}
+}
+
+// SessionFromContext returns the session loaded by the Sessions middleware,
+// or nil.
+func SessionFromContext(c *gin.Context) *Session {
+	if c == nil {
+		return nil
+	}
+	if v, exists := c.Get(sessionContextKey); exists {
+		session, _ := v.(*Session)
+		return session
+	}
+	return nil
+}
+
+// Session returns the session of the request, or nil without the Sessions
+// middleware.
+func (c *Context) Session() *Session {
+	return SessionFromContext(c.Context)
+}
+
+func loadSession(c *gin.Context, config SessionConfig) *Session {
+	now := time.Now()
+	token, err := c.Cookie(config.CookieName)
+	if err != nil || token == "" {
+		return newSession(now)
+	}
+
+	record, err := config.Store.Load(c.Request.Context(), token)
+	if err != nil {
+		_ = c.Error(err)
+	}
+	if record == nil {
+		session := newSession(now)
+		session.expireCookie = true
+		return session
+	}
+	if now.Sub(record.LastSeen) >= config.IdleTimeout || now.Sub(record.Created) >= config.AbsoluteTimeout {
+		session := newSession(now)
+		session.stale = token
+		session.expireCookie = true
+		return session
+	}
+	return &Session{record: *record, token: token}
+}
+
+func newSession(now time.Time) *Session {
+	return &Session{
+		record: SessionRecord{ID: newSessionID(), Created: now, LastSeen: now},
+		isNew:  true,
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// saveSession stores the session and sets the session cookie.
+func saveSession(c *gin.Context, config SessionConfig, session *Session) {
+	ctx := c.Request.Context()
+	if session.stale != "" {
+		if err := config.Store.Delete(ctx, session.stale); err != nil {
+			_ = c.Error(err)
+		}
+	}
+
+	cookie := &http.Cookie{
+		Name:     config.CookieName,
+		Path:     config.Path,
+		Domain:   config.Domain,
+		Secure:   config.Secure,
+		HttpOnly: true,
+		SameSite: config.SameSite,
+	}
+
+	if session.isNew && !session.changed {
+		if session.expireCookie {
+			cookie.MaxAge = -1
+			http.SetCookie(c.Writer, cookie)
+		}
+		return
+	}
+
+	now := time.Now()
+	session.record.LastSeen = now
+	ttl := min(config.IdleTimeout, session.record.Created.Add(config.AbsoluteTimeout).Sub(now))
+	token, err := config.Store.Save(ctx, &session.record, ttl)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	cookie.Value = token
+	cookie.MaxAge = ceilSeconds(ttl)
+	http.SetCookie(c.Writer, cookie)
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	return s.record.ID
+}
+
+// IsNew reports whether the session started with this request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get returns the session value of key, or nil.
+func (s *Session) Get(key string) any {
+	return s.record.Values[key]
+}
+
+// Set sets the session value of key.
+func (s *Session) Set(key string, value any) {
+	if s.record.Values == nil {
+		s.record.Values = make(map[string]any)
+	}
+	s.record.Values[key] = value
+	s.changed = true
+}
+
+// Delete removes the session value of key.
+func (s *Session) Delete(key string) {
+	if _, ok := s.record.Values[key]; ok {
+		delete(s.record.Values, key)
+		s.changed = true
+	}
+}
+
+// Values returns a copy of the session values.
+func (s *Session) Values() map[string]any {
+	return maps.Clone(s.record.Values)
+}
+
+// Regenerate gives the session a new ID and drops the old one, keeping the
+// values. Call it when the privilege level changes, such as on login, to
+// prevent session fixation.
+func (s *Session) Regenerate() {
+	if s.token != "" {
+		s.stale = s.token
+	}
+	now := time.Now()
+	s.record.ID = newSessionID()
+	s.record.Created = now
+	s.record.LastSeen = now
+	s.changed = true
+}
+
+// Destroy ends the session, for example on logout. Values set afterwards go
+// to a new session.
+func (s *Session) Destroy() {
+	stale := s.stale
+	if s.token != "" {
+		stale = s.token
+	}
+	*s = *newSession(time.Now())
+	s.stale = stale
+	s.expireCookie = true
+}
+
+// AddFlash adds a flash message, kept in the session until read by Flashes,
+// typically on the next request.
+func (s *Session) AddFlash(value any) {
+	flashes, _ := s.Get(flashesSessionKey).([]any)
+	s.Set(flashesSessionKey, append(flashes, value))
+}
+
+// Flashes returns and removes the flash messages.
+func (s *Session) Flashes() []any {
+	flashes, _ := s.Get(flashesSessionKey).([]any)
+	s.Delete(flashesSessionKey)
+	return flashes
+}
+
+// sessionWriter saves the session before the response header is sent.
+type sessionWriter struct {
+	gin.ResponseWriter
+
+	save      func()
+	committed bool
+}
+
+func (w *sessionWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	if !w.ResponseWriter.Written() {
+		w.save()
+	}
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *sessionWriter) Flush() {
+	w.commit()
+	w.ResponseWriter.Flush()
+}
+
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.committed = true
+	return w.ResponseWriter.Hijack()
+}
@@ -0,0 +1,277 @@
+package fox
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSessionTooLarge is returned by CookieSessionStore when the encoded
+// session does not fit in a cookie.
+var ErrSessionTooLarge = errors.New("session too large for a cookie")
+
+// maxSessionCookieSize is the cookie size browsers are required to support.
+const maxSessionCookieSize = 4096
+
+// SessionRecord is the persisted state of a session.
+type SessionRecord struct {
+	// ID identifies the session. It changes on Session.Regenerate.
+	ID string `json:"id"`
+
+	// Values are the session values. Stores that serialize them, such as
+	// CookieSessionStore, round-trip them through JSON.
+	Values map[string]any `json:"values,omitempty"`
+
+	// Created is when the session started, for the absolute timeout.
+	Created time.Time `json:"created"`
+
+	// LastSeen is when the session was last used, for the idle timeout.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// SessionStore keeps sessions between requests. The token is the session
+// cookie value: a session ID for server-side stores, the session itself for
+// CookieSessionStore.
+type SessionStore interface {
+	// Load returns the session of token, or nil when it is unknown, expired
+	// or tampered with.
+	Load(ctx context.Context, token string) (*SessionRecord, error)
+
+	// Save stores record for at least ttl and returns its token.
+	Save(ctx context.Context, record *SessionRecord, ttl time.Duration) (token string, err error)
+
+	// Delete forgets the session of token.
+	Delete(ctx context.Context, token string) error
+}
+
+type sessionEntry struct {
+	record  SessionRecord
+	expires time.Time
+}
+
+// MemorySessionStore is an in-process SessionStore. Expired sessions are
+// dropped lazily.
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	entries   map[string]sessionEntry
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+var _ SessionStore = (*MemorySessionStore)(nil)
+
+// NewMemorySessionStore returns an in-memory SessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		entries: make(map[string]sessionEntry),
+		now:     time.Now,
+	}
+}
+
+// Len returns the number of sessions, including expired sessions not yet
+// dropped.
+func (s *MemorySessionStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Load implements SessionStore.
+func (s *MemorySessionStore) Load(_ context.Context, token string) (*SessionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	entry, ok := s.entries[token]
+	if !ok || !now.Before(entry.expires) {
+		return nil, nil
+	}
+	record := entry.record
+	record.Values = maps.Clone(record.Values)
+	return &record, nil
+}
+
+// Save implements SessionStore.
+func (s *MemorySessionStore) Save(_ context.Context, record *SessionRecord, ttl time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *record
+	stored.Values = maps.Clone(record.Values)
+	s.entries[record.ID] = sessionEntry{record: stored, expires: s.now().Add(ttl)}
+	return record.ID, nil
+}
+
+// Delete implements SessionStore.
+func (s *MemorySessionStore) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, token)
+	return nil
+}
+
+// sweep drops expired sessions at most once a minute.
+func (s *MemorySessionStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for token, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, token)
+		}
+	}
+}
+
+// CookieSessionStoreConfig defines the config for CookieSessionStore.
+type CookieSessionStoreConfig struct {
+	// Keys sign or encrypt the sessions. The first key is used for new
+	// cookies; the others are still accepted, so that keys can be rotated by
+	// prepending a new one and dropping the oldest later. Required.
+	Keys [][]byte
+
+	// Encrypt encrypts the sessions with AES-256-GCM instead of only signing
+	// them with HMAC-SHA256, so that clients cannot read the values.
+	// Optional.
+	Encrypt bool
+}
+
+// CookieSessionStore is a SessionStore keeping the whole session in the
+// cookie, signed or encrypted. It needs no server-side state, but sessions
+// are limited to about 4KB and Delete cannot revoke a copied cookie before
+// it expires.
+type CookieSessionStore struct {
+	encrypt bool
+	keys    []cookieSessionKey
+}
+
+type cookieSessionKey struct {
+	aead cipher.AEAD // nil when signing only
+	mac  []byte
+}
+
+var _ SessionStore = (*CookieSessionStore)(nil)
+
+// NewCookieSessionStore returns a CookieSessionStore. It panics without keys.
+func NewCookieSessionStore(config CookieSessionStoreConfig) *CookieSessionStore {
+	if len(config.Keys) == 0 {
+		panic("fox: CookieSessionStoreConfig requires at least one key")
+	}
+	store := &CookieSessionStore{encrypt: config.Encrypt}
+	for _, key := range config.Keys {
+		if len(key) == 0 {
+			panic("fox: CookieSessionStoreConfig keys must not be empty")
+		}
+		k := cookieSessionKey{mac: deriveSessionKey(key, "fox session signing")}
+		if config.Encrypt {
+			block, err := aes.NewCipher(deriveSessionKey(key, "fox session encryption"))
+			if err != nil {
+				panic(err)
+			}
+			if k.aead, err = cipher.NewGCM(block); err != nil {
+				panic(err)
+			}
+		}
+		store.keys = append(store.keys, k)
+	}
+	return store
+}
+
+// deriveSessionKey derives a 32-byte key for purpose, so that keys of any
+// length can be used and signing and encryption never share a key.
+func deriveSessionKey(key []byte, purpose string) []byte {
+	return hmacSHA256(key, purpose)
+}
+
+// Load implements SessionStore.
+func (s *CookieSessionStore) Load(_ context.Context, token string) (*SessionRecord, error) {
+	for _, key := range s.keys {
+		data, ok := s.open(key, token)
+		if !ok {
+			continue
+		}
+		var record SessionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, nil
+		}
+		return &record, nil
+	}
+	return nil, nil
+}
+
+// Save implements SessionStore.
+func (s *CookieSessionStore) Save(_ context.Context, record *SessionRecord, _ time.Duration) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	token, err := s.seal(s.keys[0], data)
+	if err != nil {
+		return "", err
+	}
+	if len(token) > maxSessionCookieSize {
+		return "", ErrSessionTooLarge
+	}
+	return token, nil
+}
+
+// Delete implements SessionStore. The cookie is expired by the Sessions
+// middleware; there is nothing to forget server-side.
+func (s *CookieSessionStore) Delete(context.Context, string) error {
+	return nil
+}
+
+func (s *CookieSessionStore) seal(key cookieSessionKey, data []byte) (string, error) {
+	encoding := base64.RawURLEncoding
+	if s.encrypt {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		return encoding.EncodeToString(key.aead.Seal(nonce, nonce, data, nil)), nil
+	}
+	payload := encoding.EncodeToString(data)
+	return payload + "." + encoding.EncodeToString(hmacSHA256(key.mac, payload)), nil
+}
+
+func (s *CookieSessionStore) open(key cookieSessionKey, token string) ([]byte, bool) {
+	encoding := base64.RawURLEncoding
+	if s.encrypt {
+		sealed, err := encoding.DecodeString(token)
+		if err != nil || len(sealed) < key.aead.NonceSize() {
+			return nil, false
+		}
+		nonce, ciphertext := sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():]
+		data, err := key.aead.Open(nil, nonce, ciphertext, nil)
+		return data, err == nil
+	}
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, false
+	}
+	sum, err := encoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sum, hmacSHA256(key.mac, payload)) {
+		return nil, false
+	}
+	data, err := encoding.DecodeString(payload)
+	return data, err == nil
+}
+
+func hmacSHA256(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,113 @@
+package fox
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	store := NewMemorySessionStore()
+	store.now = clock.Now
+	ctx := context.Background()
+
+	record := &SessionRecord{ID: "abc", Values: map[string]any{"user": 1}}
+	token, err := store.Save(ctx, record, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", token)
+
+	// The stored copy is not affected by later changes.
+	record.Values["user"] = 2
+
+	loaded, err := store.Load(ctx, token)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, 1, loaded.Values["user"])
+
+	clock.Advance(time.Minute)
+	loaded, err = store.Load(ctx, token)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+	assert.Equal(t, 0, store.Len())
+
+	_, _ = store.Save(ctx, record, time.Minute)
+	require.NoError(t, store.Delete(ctx, "abc"))
+	loaded, _ = store.Load(ctx, "abc")
+	assert.Nil(t, loaded)
+}
+
+func TestCookieSessionStore(t *testing.T) {
+	ctx := context.Background()
+	created := time.Unix(1_700_000_000, 0).UTC()
+	record := &SessionRecord{
+		ID:       "abc",
+		Values:   map[string]any{"user": "alice", "n": 1},
+		Created:  created,
+		LastSeen: created,
+	}
+
+	for _, encrypt := range []bool{false, true} {
+		store := NewCookieSessionStore(CookieSessionStoreConfig{Keys: [][]byte{[]byte("secret")}, Encrypt: encrypt})
+
+		token, err := store.Save(ctx, record, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, !encrypt, strings.Contains(token, "."))
+
+		loaded, err := store.Load(ctx, token)
+		require.NoError(t, err)
+		require.NotNil(t, loaded)
+		assert.Equal(t, "abc", loaded.ID)
+		assert.Equal(t, "alice", loaded.Values["user"])
+		assert.Equal(t, float64(1), loaded.Values["n"]) // JSON round-trip
+		assert.True(t, created.Equal(loaded.Created))
+
+		tampered := token[:len(token)-2] + "AA"
+		if tampered == token {
+			tampered = token[:len(token)-2] + "BB"
+		}
+		loaded, err = store.Load(ctx, tampered)
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+
+		other := NewCookieSessionStore(CookieSessionStoreConfig{Keys: [][]byte{[]byte("other")}, Encrypt: encrypt})
+		loaded, _ = other.Load(ctx, token)
+		assert.Nil(t, loaded)
+	}
+}
+
+func TestCookieSessionStore_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	record := &SessionRecord{ID: "abc"}
+
+	old := NewCookieSessionStore(CookieSessionStoreConfig{Keys: [][]byte{[]byte("old")}, Encrypt: true})
+	token, err := old.Save(ctx, record, time.Minute)
+	require.NoError(t, err)
+
+	rotated := NewCookieSessionStore(CookieSessionStoreConfig{Keys: [][]byte{[]byte("new"), []byte("old")}, Encrypt: true})
+	loaded, err := rotated.Load(ctx, token)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, "abc", loaded.ID)
+
+	// New cookies are sealed with the first key only.
+	token, err = rotated.Save(ctx, record, time.Minute)
+	require.NoError(t, err)
+	loaded, _ = old.Load(ctx, token)
+	assert.Nil(t, loaded)
+}
+
+func TestCookieSessionStore_TooLarge(t *testing.T) {
+	store := NewCookieSessionStore(CookieSessionStoreConfig{Keys: [][]byte{[]byte("secret")}})
+	_, err := store.Save(context.Background(), &SessionRecord{
+		ID:     "abc",
+		Values: map[string]any{"blob": strings.Repeat("x", maxSessionCookieSize)},
+	}, time.Minute)
+	assert.ErrorIs(t, err, ErrSessionTooLarge)
+
+	assert.Panics(t, func() { NewCookieSessionStore(CookieSessionStoreConfig{}) })
+}
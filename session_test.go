@@ -0,0 +1,160 @@
+package fox
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionProfile struct {
+	UserID int64        `session:"user_id" json:"-"`
+	Prefs  sessionPrefs `session:"prefs" json:"-"`
+}
+
+type sessionPrefs struct {
+	Theme string `json:"theme"`
+}
+
+func newSessionRouter(store SessionStore) *Engine {
+	router := New()
+	router.Use(Sessions(SessionConfig{Store: store}))
+	router.POST("/login", func(c *Context) string {
+		session := c.Session()
+		session.Regenerate()
+		session.Set("user_id", 42)
+		session.Set("prefs", sessionPrefs{Theme: "dark"})
+		session.AddFlash("welcome")
+		return session.ID()
+	})
+	router.GET("/profile", func(c *Context, in *sessionProfile) map[string]any {
+		return map[string]any{"user": in.UserID, "theme": in.Prefs.Theme, "flashes": c.Session().Flashes()}
+	})
+	router.POST("/logout", func(c *Context) string {
+		c.Session().Destroy()
+		return "bye"
+	})
+	router.GET("/anonymous", func(c *Context) bool {
+		return c.Session().IsNew()
+	})
+	return router
+}
+
+func TestSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	stores := map[string]SessionStore{
+		"memory": NewMemorySessionStore(),
+		"cookie": NewCookieSessionStore(CookieSessionStoreConfig{Keys: [][]byte{[]byte("secret")}, Encrypt: true}),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			router := newSessionRouter(store)
+
+			w := serve(router, http.MethodGet, "/anonymous", "")
+			assert.Equal(t, "true", w.Body.String())
+			assert.Empty(t, w.Result().Cookies(), "untouched new sessions are not stored")
+
+			w = serve(router, http.MethodPost, "/login", "")
+			require.Equal(t, http.StatusOK, w.Code)
+			cookie := findCookie(w, DefaultSessionCookie)
+			assert.True(t, cookie.HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+			assert.Equal(t, 1800, cookie.MaxAge)
+
+			w = serve(router, http.MethodGet, "/profile", "", "Cookie", cookie.Name+"="+cookie.Value)
+			assert.JSONEq(t, `{"user":42,"theme":"dark","flashes":["welcome"]}`, w.Body.String())
+			cookie = findCookie(w, DefaultSessionCookie)
+
+			// Flashes are read once.
+			w = serve(router, http.MethodGet, "/profile", "", "Cookie", cookie.Name+"="+cookie.Value)
+			assert.JSONEq(t, `{"user":42,"theme":"dark","flashes":null}`, w.Body.String())
+			cookie = findCookie(w, DefaultSessionCookie)
+
+			w = serve(router, http.MethodPost, "/logout", "", "Cookie", cookie.Name+"="+cookie.Value)
+			assert.Equal(t, -1, findCookie(w, DefaultSessionCookie).MaxAge)
+
+			if name == "memory" {
+				// The server-side session is gone even if the cookie is replayed.
+				w = serve(router, http.MethodGet, "/profile", "", "Cookie", cookie.Name+"="+cookie.Value)
+				assert.JSONEq(t, `{"user":0,"theme":"","flashes":null}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSessions_Regenerate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := NewMemorySessionStore()
+	router := newSessionRouter(store)
+
+	w := serve(router, http.MethodPost, "/login", "")
+	first := findCookie(w, DefaultSessionCookie)
+	assert.Equal(t, first.Value, w.Body.String())
+
+	w = serve(router, http.MethodPost, "/login", "", "Cookie", first.Name+"="+first.Value)
+	second := findCookie(w, DefaultSessionCookie)
+	assert.NotEqual(t, first.Value, second.Value)
+	assert.Equal(t, 1, store.Len(), "the fixated ID is dropped")
+}
+
+func TestSessions_Timeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		age   func(*SessionRecord)
+		valid bool
+	}{
+		{"active", func(r *SessionRecord) { r.LastSeen = r.LastSeen.Add(-10 * time.Minute) }, true},
+		{"idle", func(r *SessionRecord) { r.LastSeen = r.LastSeen.Add(-31 * time.Minute) }, false},
+		{"absolute", func(r *SessionRecord) { r.Created = r.Created.Add(-25 * time.Hour) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemorySessionStore()
+			router := newSessionRouter(store)
+
+			cookie := findCookie(serve(router, http.MethodPost, "/login", ""), DefaultSessionCookie)
+			entry := store.entries[cookie.Value]
+			tt.age(&entry.record)
+			store.entries[cookie.Value] = entry
+
+			w := serve(router, http.MethodGet, "/anonymous", "", "Cookie", cookie.Name+"="+cookie.Value)
+			assert.Equal(t, tt.valid, w.Body.String() == "false")
+			if !tt.valid {
+				assert.Equal(t, -1, findCookie(w, DefaultSessionCookie).MaxAge)
+				assert.Equal(t, 0, store.Len())
+			}
+		})
+	}
+}
+
+func TestSessions_BindTypeMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(Sessions(SessionConfig{Store: NewMemorySessionStore()}))
+	router.POST("/set", func(c *Context) string {
+		c.Session().Set("user_id", []string{"x"})
+		return "ok"
+	})
+	router.GET("/profile", func(c *Context, in *sessionProfile) int64 { return in.UserID })
+
+	cookie := findCookie(serve(router, http.MethodPost, "/set", ""), DefaultSessionCookie)
+	w := serve(router, http.MethodGet, "/profile", "", "Cookie", cookie.Name+"="+cookie.Value)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Without the middleware, session fields are left alone.
+	plain := New()
+	plain.GET("/profile", func(c *Context, in *sessionProfile) int64 {
+		assert.Nil(t, c.Session())
+		return in.UserID
+	})
+	w = serve(plain, http.MethodGet, "/profile", "")
+	assert.Equal(t, "0", w.Body.String())
+}
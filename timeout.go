@@ -0,0 +1,260 @@
+package fox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+)
+
+// ErrTimeoutHijack is returned when a handler running under Timeout tries to
+// hijack the connection.
+var ErrTimeoutHijack = errors.New("fox: hijack is not supported under a timeout")
+
+// Timeout returns a middleware that gives the rest of the chain a deadline.
+// Handlers observe it through Context.Done and Context.Err. When the deadline
+// passes first, err is sent right away instead, 503
+// httperrors.ErrServiceUnavailable by default, rendered like handler errors,
+// and everything the abandoned handler writes afterwards is discarded. The
+// timeout is recorded as the "timeout" route metadata.
+//
+// The response is buffered until the handler returns, so streaming and
+// hijacking are not available under a timeout. The middleware still waits for
+// the handler to return before releasing the request, so handlers should stop
+// once their context is done.
+//
+// Attach it to a group or a single route:
+//
+//	api := router.Group("/api", fox.Timeout(5*time.Second))
+//	api.GET("/report", fox.Timeout(30*time.Second, httperrors.ErrGatewayTimeout), Report)
+func Timeout(timeout time.Duration, err ...*httperrors.Error) *Middleware {
+	timeoutErr := httperrors.ErrServiceUnavailable
+	if len(err) > 0 && err[0] != nil {
+		timeoutErr = err[0]
+	}
+
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+
+			original := c.Writer
+			tw := newTimeoutWriter(ctx, original)
+			// The timeout error is rendered on a copy of the context, which
+			// the abandoned handler does not use.
+			errCtx := c.Copy()
+			c.Writer = tw
+
+			var (
+				done       = make(chan struct{})
+				panicValue any
+			)
+			go func() {
+				defer close(done)
+				defer func() {
+					panicValue = recover()
+				}()
+				c.Next()
+			}()
+
+			// A canceled parent means the client is gone; only a passed
+			// deadline answers with the timeout error. The handler may
+			// also return after the deadline before it is observed here.
+			select {
+			case <-done:
+				if tw.expire() {
+					writeTimeoutError(errCtx, original, timeoutErr)
+				}
+			case <-ctx.Done():
+				if tw.expire() {
+					writeTimeoutError(errCtx, original, timeoutErr)
+					original.Flush()
+				}
+				<-done
+			}
+			c.Writer = original
+
+			if !tw.timedOut() {
+				if panicValue != nil {
+					panic(panicValue)
+				}
+				tw.flushTo(original)
+				return
+			}
+
+			log := requestLogger(c)
+			fields := map[string]any{
+				"method":  c.Request.Method,
+				"route":   c.FullPath(),
+				"timeout": timeout.String(),
+			}
+			if panicValue != nil {
+				fields["panic"] = panicValue
+			}
+			log.WithFields(fields).Warn("handler timed out")
+			_ = c.Error(timeoutErr)
+			c.Abort()
+		},
+		Meta: RouteMeta{"timeout": timeout.String()},
+	}
+}
+
+// writeTimeoutError renders err on c, a copy of the request context, and
+// sends it to w.
+func writeTimeoutError(c *gin.Context, w gin.ResponseWriter, err *httperrors.Error) {
+	buf := newTimeoutWriter(context.Background(), w)
+	c.Writer = buf
+	AbortWithError(c, err)
+	buf.flushTo(w)
+}
+
+// timeoutWriter buffers the response of a handler running under Timeout.
+type timeoutWriter struct {
+	ctx      context.Context
+	original gin.ResponseWriter
+
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	size     int
+	timedout bool
+}
+
+var _ gin.ResponseWriter = (*timeoutWriter)(nil)
+
+func newTimeoutWriter(ctx context.Context, original gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ctx:      ctx,
+		original: original,
+		header:   original.Header().Clone(),
+		status:   original.Status(),
+		size:     -1,
+	}
+}
+
+// expire marks the writer as abandoned once the deadline of its context has
+// passed, and reports whether it is; later writes are discarded.
+func (w *timeoutWriter) expire() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.expireLocked()
+}
+
+func (w *timeoutWriter) expireLocked() bool {
+	if !w.timedout && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedout = true
+	}
+	return w.timedout
+}
+
+func (w *timeoutWriter) timedOut() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.timedout
+}
+
+// flushTo copies the buffered response to the original writer.
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	header := dst.Header()
+	for key := range header {
+		if _, ok := w.header[key]; !ok {
+			delete(header, key)
+		}
+	}
+	maps.Copy(header, w.header)
+
+	dst.WriteHeader(w.status)
+	if w.size < 0 {
+		return
+	}
+	if w.buf.Len() == 0 {
+		dst.WriteHeaderNow()
+		return
+	}
+	_, _ = dst.Write(w.buf.Bytes())
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && w.size < 0 {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size < 0 {
+		w.size = 0
+	}
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.expireLocked() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	n, err := w.buf.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size >= 0
+}
+
+// Flush is a no-op: the response is only sent once the handler returns.
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, ErrTimeoutHijack
+}
+
+func (w *timeoutWriter) CloseNotify() <-chan bool {
+	return w.original.CloseNotify()
+}
+
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
@@ -0,0 +1,209 @@
+package fox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+	"github.com/fox-gonic/fox/logger"
+)
+
+func TestTimeout_FastHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.GET("/fast", Timeout(time.Second), func(c *Context) (map[string]string, error) {
+		c.Header("X-Handler", "fast")
+		return map[string]string{"status": "ok"}, nil
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	assert.Equal(t, "fast", w.Header().Get("X-Handler"))
+}
+
+func TestTimeout_StatusWithoutBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.DELETE("/item", Timeout(time.Second), func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/item", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestTimeout_DeadlineExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctxErr   error
+		writeErr error
+	)
+	router := New()
+	router.GET("/slow", Timeout(20*time.Millisecond), func(c *Context) {
+		<-c.Done()
+		ctxErr = c.Err()
+		c.Header("X-Late", "true")
+		_, writeErr = c.Writer.WriteString("late write")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Header().Get("X-Late"))
+	assert.NotContains(t, w.Body.String(), "late write")
+	require.ErrorIs(t, writeErr, http.ErrHandlerTimeout)
+	require.Error(t, ctxErr)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "SERVICE_UNAVAILABLE", body["code"])
+}
+
+func TestTimeout_WriteAfterDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Writes racing the middleware past the deadline are discarded.
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	tw := newTimeoutWriter(ctx, c.Writer)
+
+	_, err := tw.WriteString("late write")
+	require.ErrorIs(t, err, http.ErrHandlerTimeout)
+	assert.True(t, tw.timedOut())
+	assert.Equal(t, -1, tw.Size())
+}
+
+func TestTimeout_CustomErrorAndLateRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var errs []*gin.Error
+	router := New()
+	router.Use(gin.HandlerFunc(func(c *gin.Context) {
+		c.Next()
+		errs = c.Errors
+	}))
+	router.GET("/slow", Timeout(10*time.Millisecond, httperrors.ErrGatewayTimeout), func(*Context) string {
+		// Ignores the context and renders after the deadline.
+		time.Sleep(50 * time.Millisecond)
+		return "too late"
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NotContains(t, w.Body.String(), "too late")
+	require.NotEmpty(t, errs)
+	assert.ErrorIs(t, errs[len(errs)-1].Err, httperrors.ErrGatewayTimeout)
+}
+
+func TestTimeout_SendsErrorAtDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Envelope = DefaultEnvelope{}
+	router.GET("/slow", Timeout(50*time.Millisecond), func(*Context) string {
+		// Ignores the context.
+		time.Sleep(500 * time.Millisecond)
+		return "too late"
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.Get(server.URL + "/slow")
+	require.NoError(t, err)
+	elapsed := time.Since(start)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Less(t, elapsed, 400*time.Millisecond, "the error is sent before the handler returns")
+
+	var body struct {
+		Error     map[string]any `json:"error"`
+		RequestID string         `json:"request_id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "SERVICE_UNAVAILABLE", body.Error["code"])
+	assert.Equal(t, resp.Header.Get(logger.TraceID), body.RequestID)
+}
+
+func TestTimeout_GroupAndRouteMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	api := router.Group("/api", Timeout(5*time.Second))
+	api.GET("/default", func() string { return "ok" })
+	api.GET("/report", Timeout(30*time.Second), func() string { return "ok" })
+	router.GET("/plain", func() string { return "ok" })
+
+	meta := map[string]RouteMeta{}
+	for _, route := range router.HandlerRoutes() {
+		meta[route.Path] = route.Meta
+	}
+	assert.Equal(t, "5s", meta["/api/default"]["timeout"])
+	assert.Equal(t, "30s", meta["/api/report"]["timeout"])
+	assert.Nil(t, meta["/plain"])
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/report", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+}
+
+func TestTimeout_PanicIsPropagated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	router.Use(Recovery())
+	router.GET("/panic", Timeout(time.Second), func() string {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestTimeout_HijackNotSupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var hijackErr error
+	router := New()
+	router.GET("/ws", Timeout(time.Second), gin.HandlerFunc(func(c *gin.Context) {
+		_, _, hijackErr = c.Writer.Hijack()
+		c.Status(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	router.ServeHTTP(w, req)
+
+	assert.ErrorIs(t, hijackErr, ErrTimeoutHijack)
+}
@@ -0,0 +1,159 @@
+package fox
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/logger"
+	"github.com/fox-gonic/fox/tracing"
+)
+
+// tracingContextKey stores the TracingConfig of the current request in the
+// gin context so that handleWrapper can create per-handler spans.
+const tracingContextKey = "_fox-gonic/fox/tracing/config"
+
+// TracingConfig defines the config for Tracing middleware.
+type TracingConfig struct {
+	// Tracer creates the spans. Required.
+	Tracer *tracing.Tracer
+
+	// HandlerSpans creates a child span for every fox handler in the chain,
+	// named by the route template and the handler name.
+	// Optional.
+	HandlerSpans bool
+
+	// SkipPaths is an url path array which are not traced.
+	// Optional.
+	SkipPaths []string
+}
+
+// Tracing middleware starts a server span per request, continuing the trace
+// from the W3C traceparent/tracestate request headers when present. The span
+// is named by the HTTP method and route template. Requests without request ID
+// header use the trace ID as request ID, surfaced through Context.TraceID and
+// the X-Request-Id response header, and the request logger carries the
+// trace_id and span_id fields.
+//
+// Register Tracing before Logger so that the access log carries the trace ID
+// as its request ID; the request logger is enriched in either order.
+func Tracing(config TracingConfig) gin.HandlerFunc {
+	if config.Tracer == nil {
+		panic("fox: tracing requires a tracer")
+	}
+
+	var skip map[string]struct{}
+	if length := len(config.SkipPaths); length > 0 {
+		skip = make(map[string]struct{}, length)
+		for _, path := range config.SkipPaths {
+			skip[path] = struct{}{}
+		}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		if remote, ok := tracing.Extract(c.Request.Header); ok {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, remote)
+		}
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx, span := config.Tracer.Start(ctx, name,
+			tracing.WithSpanKind(tracing.SpanKindServer),
+			tracing.WithAttributes(map[string]any{
+				"http.request.method": c.Request.Method,
+				"http.route":          route,
+				"url.path":            c.Request.URL.Path,
+			}),
+		)
+		defer span.End()
+
+		sc := span.SpanContext()
+		traceID := sc.TraceID.String()
+
+		c.Request = c.Request.WithContext(ctx)
+		if c.Request.Header.Get(logger.TraceID) == "" {
+			c.Request.Header.Set(logger.TraceID, traceID)
+		}
+		// With Logger registered first, enrich its logger now. Otherwise
+		// Logger enriches it from the span.
+		if v, exists := c.Get(LoggerContextKey); exists {
+			if log, ok := v.(logger.Logger); ok {
+				c.Set(LoggerContextKey, log.WithFields(traceLogFields(sc)))
+			}
+		}
+		c.Set(tracingContextKey, &config)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.SetError(&tracingStatusError{status: status})
+		} else if err := c.Errors.Last(); err != nil {
+			span.SetError(err.Err)
+		}
+	}
+}
+
+// traceLogFields returns the logger fields of a span.
+func traceLogFields(sc tracing.SpanContext) map[string]any {
+	return map[string]any{
+		"trace_id": sc.TraceID.String(),
+		"span_id":  sc.SpanID.String(),
+	}
+}
+
+type tracingStatusError struct {
+	status int
+}
+
+func (e *tracingStatusError) Error() string {
+	return strings.ToLower(http.StatusText(e.status))
+}
+
+// startHandlerSpan starts a span around a single fox handler when the Tracing
+// middleware asked for per-handler spans. The returned function ends it and
+// makes the parent span current again, so that handlers in the chain are
+// siblings rather than nested in each other.
+func startHandlerSpan(c *gin.Context, handlerName string) func(c *gin.Context, res any) {
+	v, exists := c.Get(tracingContextKey)
+	if !exists {
+		return nil
+	}
+	config, ok := v.(*TracingConfig)
+	if !ok || !config.HandlerSpans {
+		return nil
+	}
+
+	name := handlerName
+	if route := c.FullPath(); route != "" {
+		name = route + " " + handlerName
+	}
+
+	parent := tracing.SpanFromContext(c.Request.Context())
+	ctx, span := config.Tracer.Start(c.Request.Context(), name,
+		tracing.WithAttributes(map[string]any{"fox.handler": handlerName}),
+	)
+	c.Request = c.Request.WithContext(ctx)
+
+	return func(c *gin.Context, res any) {
+		if err, ok := res.(error); ok {
+			span.SetError(err)
+		}
+		span.End()
+		if parent != nil && tracing.SpanFromContext(c.Request.Context()) == span {
+			c.Request = c.Request.WithContext(tracing.ContextWithSpan(c.Request.Context(), parent))
+		}
+	}
+}
@@ -0,0 +1,365 @@
+// Package tracing provides minimal OpenTelemetry-compatible request tracing
+// with W3C Trace Context (traceparent/tracestate) propagation.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// W3C Trace Context header names.
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// FlagsSampled is the W3C sampled trace flag.
+const FlagsSampled byte = 0x01
+
+// ErrInvalidTraceparent is returned when a traceparent header is malformed.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID is a W3C trace identifier.
+type TraceID [16]byte
+
+// IsValid reports whether the trace ID is non-zero.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the lowercase hex encoding of the trace ID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID is a W3C span (parent) identifier.
+type SpanID [8]byte
+
+// IsValid reports whether the span ID is non-zero.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the lowercase hex encoding of the span ID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is the propagated part of a span.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags byte
+	TraceState string
+	Remote     bool
+}
+
+// IsValid reports whether both the trace and span IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled reports whether the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&FlagsSampled != 0
+}
+
+// Traceparent returns the W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	var b strings.Builder
+	b.Grow(55)
+	b.WriteString("00-")
+	b.WriteString(sc.TraceID.String())
+	b.WriteByte('-')
+	b.WriteString(sc.SpanID.String())
+	b.WriteByte('-')
+	b.WriteString(hex.EncodeToString([]byte{sc.TraceFlags}))
+	return b.String()
+}
+
+// ParseTraceparent parses a W3C traceparent header value. Future versions are
+// accepted as long as the version 00 fields can be read.
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return sc, ErrInvalidTraceparent
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+
+	version, err := decodeHex(value[0:2])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(value) != 55) {
+		return sc, ErrInvalidTraceparent
+	}
+
+	traceID, err := decodeHex(value[3:35])
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	spanID, err := decodeHex(value[36:52])
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	flags, err := decodeHex(value[53:55])
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.TraceFlags = flags[0]
+	sc.Remote = true
+
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex only, as required by the W3C spec.
+func decodeHex(s string) ([]byte, error) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return nil, ErrInvalidTraceparent
+		}
+	}
+	return hex.DecodeString(s)
+}
+
+// Extract reads the span context from W3C headers. The second result is
+// false when the headers carry no valid trace context.
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = header.Get(TracestateHeader)
+	return sc, true
+}
+
+// Inject writes the span context of ctx as W3C headers, for example into an
+// outgoing request. It does nothing when ctx carries no span.
+func Inject(ctx context.Context, header http.Header) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	sc := span.SpanContext()
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// SpanKind describes the relationship of a span to its parent, mirroring the
+// OpenTelemetry span kinds.
+type SpanKind int
+
+// Span kinds.
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+// String returns the span kind name.
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// SpanData is the immutable snapshot of a finished span handed to exporters.
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]any
+	Err          error
+}
+
+// Duration returns the span duration.
+func (d SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// Span is an in-progress operation. It is safe for concurrent use.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context.
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// SetAttribute records an attribute on the span.
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]any)
+	}
+	s.data.Attributes[key] = value
+}
+
+// SetError marks the span as failed.
+func (s *Span) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+// End finishes the span and exports it when sampled. Only the first call has
+// an effect.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	if s.data.Attributes != nil {
+		data.Attributes = make(map[string]any, len(s.data.Attributes))
+		for k, v := range s.data.Attributes {
+			data.Attributes[k] = v
+		}
+	}
+	s.mu.Unlock()
+
+	if s.tracer.exporter != nil && data.SpanContext.IsSampled() {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+type remoteContextKey struct{}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying a span context
+// received from another process, used as parent by the next Start.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteContextKey{}, sc)
+}
+
+// Exporter receives finished, sampled spans. Implementations bridge to a
+// tracing backend, for example an OpenTelemetry SDK exporter.
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+// Tracer creates spans and hands them to its exporter when they end.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a tracer exporting to exporter. A nil exporter creates
+// spans for propagation only.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// StartOption configures a span at creation.
+type StartOption func(*SpanData)
+
+// WithSpanKind sets the span kind.
+func WithSpanKind(kind SpanKind) StartOption {
+	return func(d *SpanData) {
+		d.Kind = kind
+	}
+}
+
+// WithAttributes sets initial span attributes.
+func WithAttributes(attributes map[string]any) StartOption {
+	return func(d *SpanData) {
+		if d.Attributes == nil {
+			d.Attributes = make(map[string]any, len(attributes))
+		}
+		for k, v := range attributes {
+			d.Attributes[k] = v
+		}
+	}
+}
+
+// Start creates a span that is a child of the span or remote span context
+// carried by ctx, or a new root span, and returns ctx carrying the new span.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:      name,
+			StartTime: time.Now(),
+		},
+	}
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		psc := parent.SpanContext()
+		span.data.SpanContext.TraceID = psc.TraceID
+		span.data.SpanContext.TraceFlags = psc.TraceFlags
+		span.data.SpanContext.TraceState = psc.TraceState
+		span.data.ParentSpanID = psc.SpanID
+	} else if remote, ok := ctx.Value(remoteContextKey{}).(SpanContext); ok && remote.IsValid() {
+		span.data.SpanContext.TraceID = remote.TraceID
+		span.data.SpanContext.TraceFlags = remote.TraceFlags
+		span.data.SpanContext.TraceState = remote.TraceState
+		span.data.ParentSpanID = remote.SpanID
+	} else {
+		span.data.SpanContext.TraceID = newTraceID()
+		span.data.SpanContext.TraceFlags = FlagsSampled
+	}
+	span.data.SpanContext.SpanID = newSpanID()
+
+	for _, opt := range opts {
+		opt(&span.data)
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(validTraceparent)
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.True(t, sc.Remote)
+	assert.Equal(t, validTraceparent, sc.Traceparent())
+}
+
+func TestParseTraceparent_FutureVersion(t *testing.T) {
+	sc, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	require.NoError(t, err)
+	assert.False(t, sc.IsSampled())
+}
+
+func TestParseTraceparent_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	}
+	for _, value := range tests {
+		_, err := ParseTraceparent(value)
+		require.ErrorIs(t, err, ErrInvalidTraceparent, value)
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, validTraceparent)
+	header.Set(TracestateHeader, "vendor=value")
+
+	sc, ok := Extract(header)
+	require.True(t, ok)
+	assert.Equal(t, "vendor=value", sc.TraceState)
+
+	tracer := NewTracer(nil)
+	ctx, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), sc), "op")
+	defer span.End()
+
+	out := http.Header{}
+	Inject(ctx, out)
+	child, err := ParseTraceparent(out.Get(TraceparentHeader))
+	require.NoError(t, err)
+	assert.Equal(t, sc.TraceID, child.TraceID)
+	assert.Equal(t, span.SpanContext().SpanID, child.SpanID)
+	assert.Equal(t, "vendor=value", out.Get(TracestateHeader))
+
+	_, ok = Extract(http.Header{})
+	assert.False(t, ok)
+
+	empty := http.Header{}
+	Inject(context.Background(), empty)
+	assert.Empty(t, empty)
+}
+
+func TestTracer_StartAndExport(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root", WithSpanKind(SpanKindServer))
+	_, child := tracer.Start(ctx, "child", WithAttributes(map[string]any{"k": "v"}))
+	child.SetAttribute("n", 1)
+	child.SetError(errors.New("failed"))
+	child.End()
+	child.End()
+	root.End()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, SpanKindInternal, spans[0].Kind)
+	assert.Equal(t, root.SpanContext().TraceID, spans[0].SpanContext.TraceID)
+	assert.Equal(t, root.SpanContext().SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, map[string]any{"k": "v", "n": 1}, spans[0].Attributes)
+	require.EqualError(t, spans[0].Err, "failed")
+	assert.GreaterOrEqual(t, spans[0].Duration(), time.Duration(0))
+
+	assert.Equal(t, "root", spans[1].Name)
+	assert.Equal(t, "server", spans[1].Kind.String())
+	assert.False(t, spans[1].ParentSpanID.IsValid())
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestTracer_UnsampledRemoteParentIsNotExported(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+
+	_, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), sc), "op")
+	span.End()
+
+	assert.Equal(t, sc.TraceID, span.SpanContext().TraceID)
+	assert.Empty(t, exporter.Spans())
+}
@@ -0,0 +1,168 @@
+package fox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fox-gonic/fox/httperrors"
+	"github.com/fox-gonic/fox/logger"
+	"github.com/fox-gonic/fox/tracing"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTracing_ContinuesW3CTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracing.NewInMemoryExporter()
+	router := New()
+	router.Use(Tracing(TracingConfig{Tracer: tracing.NewTracer(exporter)}), Logger())
+
+	var traceID, loggerTraceID string
+	router.GET("/users/:id", func(c *Context) string {
+		traceID = c.TraceID()
+		loggerTraceID = c.Logger.TraceID()
+		return "ok"
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set(tracing.TraceparentHeader, testTraceparent)
+	req.Header.Set(tracing.TracestateHeader, "vendor=value")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, traceID, loggerTraceID)
+	assert.Equal(t, traceID, w.Header().Get(logger.TraceID))
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /users/:id", span.Name)
+	assert.Equal(t, tracing.SpanKindServer, span.Kind)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID.String())
+	assert.Equal(t, "vendor=value", span.SpanContext.TraceState)
+	assert.Equal(t, "/users/:id", span.Attributes["http.route"])
+	assert.Equal(t, http.StatusOK, span.Attributes["http.response.status_code"])
+	assert.NoError(t, span.Err)
+}
+
+func TestTracing_NewTraceAndHandlerSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracing.NewInMemoryExporter()
+	router := New()
+	router.Use(Tracing(TracingConfig{Tracer: tracing.NewTracer(exporter), HandlerSpans: true}))
+
+	var traceID string
+	router.GET("/fail", func(c *Context) {
+		c.Set("seen", true)
+	}, func(c *Context) (any, error) {
+		traceID = c.TraceID()
+		return nil, httperrors.ErrInternalServerError
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Len(t, traceID, 32)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 3)
+
+	server := spans[2]
+	assert.Equal(t, "GET /fail", server.Name)
+	assert.Equal(t, traceID, server.SpanContext.TraceID.String())
+	assert.False(t, server.ParentSpanID.IsValid())
+	require.Error(t, server.Err)
+
+	for _, span := range spans[:2] {
+		assert.Contains(t, span.Name, "/fail ")
+		assert.Equal(t, server.SpanContext.SpanID, span.ParentSpanID, "handler spans are siblings")
+	}
+	assert.NoError(t, spans[0].Err)
+	assert.ErrorIs(t, spans[1].Err, httperrors.ErrInternalServerError)
+}
+
+func TestTracing_SkipPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracing.NewInMemoryExporter()
+	router := New()
+	router.Use(Tracing(TracingConfig{Tracer: tracing.NewTracer(exporter), SkipPaths: []string{"/skip"}}))
+	router.GET("/skip", func() string { return "ok" })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/skip", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestTracing_RequiresTracer(t *testing.T) {
+	assert.Panics(t, func() {
+		Tracing(TracingConfig{})
+	})
+}
+
+func TestLogger_UsesTraceparentAsRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Logger())
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(tracing.TraceparentHeader, testTraceparent)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", w.Header().Get(logger.TraceID))
+}
+
+func TestTracing_KeepsClientRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, middlewares := range map[string]func(gin.HandlerFunc) []HandlerFunc{
+		"tracing first": func(tr gin.HandlerFunc) []HandlerFunc { return []HandlerFunc{tr, Logger()} },
+		"logger first":  func(tr gin.HandlerFunc) []HandlerFunc { return []HandlerFunc{Logger(), tr} },
+		"no logger":     func(tr gin.HandlerFunc) []HandlerFunc { return []HandlerFunc{tr} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			exporter := tracing.NewInMemoryExporter()
+			router := New()
+			router.Use(middlewares(Tracing(TracingConfig{Tracer: tracing.NewTracer(exporter)}))...)
+
+			var traceID string
+			router.GET("/", func(c *Context) string {
+				traceID = c.TraceID()
+				return "ok"
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(logger.TraceID, "client-id")
+			req.Header.Set(tracing.TraceparentHeader, testTraceparent)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "client-id", traceID)
+			assert.Equal(t, "client-id", w.Header().Get(logger.TraceID))
+
+			spans := exporter.Spans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID.String())
+		})
+	}
+}
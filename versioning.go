@@ -0,0 +1,325 @@
+package fox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fox-gonic/fox/httperrors"
+	"github.com/fox-gonic/fox/utils"
+)
+
+// DefaultVersionHeader is the usual VersioningConfig.Header.
+const DefaultVersionHeader = "Accept-Version"
+
+// apiVersionContextKey stores the API version of the current request.
+const apiVersionContextKey = "_fox-gonic/fox/api-version"
+
+// ErrAPIVersionUnsupported is returned when a request names an unknown API
+// version, or none without a default version.
+var ErrAPIVersionUnsupported = &httperrors.Error{
+	HTTPCode: http.StatusBadRequest,
+	Err:      errors.New("unsupported API version"),
+	Code:     "API_VERSION_UNSUPPORTED",
+}
+
+// VersioningConfig defines how requests select an API version.
+type VersioningConfig struct {
+	// Prefix of the version path segment, default is "v": routes of version
+	// "2" are served under "/v2".
+	// Optional.
+	Prefix string
+
+	// Header carries the version of requests to unversioned paths, for
+	// example "Accept-Version".
+	// Optional.
+	Header string
+
+	// Vendor selects the version of requests to unversioned paths from the
+	// Accept media type, "application/vnd.<Vendor>.v2+json" or
+	// "application/vnd.<Vendor>+json; version=2". The media type takes
+	// precedence over Header.
+	// Optional.
+	Vendor string
+
+	// Default is the version of requests to unversioned paths that name
+	// none. Without it, such requests fail with ErrAPIVersionUnsupported.
+	// Optional.
+	Default string
+
+	// Deprecated maps retired versions to their deprecation, announced on
+	// each response with the Deprecation, Sunset and Link headers.
+	// Optional.
+	Deprecated map[string]VersionDeprecation
+}
+
+// VersionDeprecation describes the retirement of an API version.
+type VersionDeprecation struct {
+	// At is when the version was deprecated. Required.
+	At time.Time
+
+	// Sunset is when the version stops being served.
+	// Optional.
+	Sunset time.Time
+
+	// Link documents the deprecation, such as a migration guide.
+	// Optional.
+	Link string
+}
+
+// Versioning serves several versions of the routes of a group side by side.
+type Versioning struct {
+	group    *RouterGroup
+	config   VersioningConfig
+	versions map[string]*apiVersion
+	// shared records the unversioned routes dispatching to the versions.
+	shared map[handlerRouteKey]bool
+}
+
+// apiVersion is the version of the routes of a version group.
+type apiVersion struct {
+	versioning  *Versioning
+	name        string
+	basePath    string
+	deprecation *VersionDeprecation
+}
+
+// Versioning returns the versioning of the routes of the group. Routes of
+// each version are registered on the group returned by Versioning.Version and
+// served under the version path segment, such as "/api/v2/users". When the
+// config has a Header, Vendor or Default, the unversioned path "/api/users"
+// serves the version named by the request, with the version segment added to
+// the request URL path. Routes registered at an unversioned path before the
+// versioned routes take precedence; registering them after panics.
+//
+// The version of the request is available from Context.APIVersion, and is
+// recorded in RouteInfo.Version, the "version" route metadata and the route
+// manifest.
+//
+//	versions := router.Group("/api").Versioning(fox.VersioningConfig{
+//		Header:  fox.DefaultVersionHeader,
+//		Vendor:  "acme",
+//		Default: "2",
+//		Deprecated: map[string]fox.VersionDeprecation{
+//			"1": {At: deprecatedAt, Sunset: sunsetAt},
+//		},
+//	})
+//	versions.Version("1").GET("/users", ListUsersV1)
+//	versions.Version("2").GET("/users", ListUsersV2)
+func (group *RouterGroup) Versioning(config VersioningConfig) *Versioning {
+	if config.Prefix == "" {
+		config.Prefix = "v"
+	}
+	return &Versioning{
+		group:    group,
+		config:   config,
+		versions: make(map[string]*apiVersion),
+		shared:   make(map[handlerRouteKey]bool),
+	}
+}
+
+// Version returns the group of the routes of version name.
+func (v *Versioning) Version(name string, handlers ...HandlerFunc) *RouterGroup {
+	if name == "" || strings.Contains(name, "/") {
+		panic("fox: invalid API version " + strconv.Quote(name))
+	}
+
+	version, ok := v.versions[name]
+	if !ok {
+		version = &apiVersion{
+			versioning: v,
+			name:       name,
+			basePath:   utils.JoinPaths(v.group.router.BasePath(), "/"+v.config.Prefix+name),
+		}
+		if deprecation, ok := v.config.Deprecated[name]; ok {
+			version.deprecation = &deprecation
+		}
+		v.versions[name] = version
+	}
+
+	group := v.group.Group("/"+v.config.Prefix+name, append([]HandlerFunc{version.middleware()}, handlers...)...)
+	group.version = version
+	return group
+}
+
+// APIVersionFromContext returns the API version of the request, or "" for
+// unversioned routes.
+func APIVersionFromContext(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	return c.GetString(apiVersionContextKey)
+}
+
+// APIVersion returns the API version of the request, or "" for unversioned
+// routes.
+func (c *Context) APIVersion() string {
+	return APIVersionFromContext(c.Context)
+}
+
+func (version *apiVersion) middleware() *Middleware {
+	return &Middleware{
+		Handler: func(c *gin.Context) {
+			c.Set(apiVersionContextKey, version.name)
+			if d := version.deprecation; d != nil {
+				c.Header("Deprecation", "@"+strconv.FormatInt(d.At.Unix(), 10))
+				if !d.Sunset.IsZero() {
+					c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+				}
+				if d.Link != "" {
+					c.Writer.Header().Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+				}
+			}
+		},
+		Meta: RouteMeta{"version": version},
+	}
+}
+
+// String returns the version name, or "" for nil.
+func (version *apiVersion) String() string {
+	if version == nil {
+		return ""
+	}
+	return version.name
+}
+
+// MarshalJSON describes the version in the route manifest.
+func (version *apiVersion) MarshalJSON() ([]byte, error) {
+	description := map[string]any{"name": version.name}
+	if d := version.deprecation; d != nil {
+		description["deprecated"] = d.At.UTC().Format(time.RFC3339)
+		if !d.Sunset.IsZero() {
+			description["sunset"] = d.Sunset.UTC().Format(time.RFC3339)
+		}
+		if d.Link != "" {
+			description["link"] = d.Link
+		}
+	}
+	return json.Marshal(description)
+}
+
+// share registers the unversioned route of a versioned route once per method
+// and path. It is recorded in the route registry with the handlers and
+// metadata of the first versioned route, so that the route manifest, Allow
+// headers, OPTIONS requests and CORS preflight requests cover it. Routes
+// registered at the unversioned path before take precedence.
+func (version *apiVersion) share(method, absolutePath string, handlers HandlersChain, meta RouteMeta) {
+	v := version.versioning
+	if v.config.Header == "" && v.config.Vendor == "" && v.config.Default == "" {
+		return
+	}
+
+	path := utils.JoinPaths(v.group.router.BasePath(), strings.TrimPrefix(absolutePath, version.basePath))
+	key := handlerRouteKey{Method: method, Path: path}
+	if v.shared[key] {
+		return
+	}
+	v.shared[key] = true
+
+	engine := v.group.engine
+	for _, route := range engine.Engine.Routes() {
+		if route.Method == method && route.Path == path {
+			return
+		}
+	}
+
+	meta = maps.Clone(meta)
+	delete(meta, "version")
+	engine.registerHandlerRoute(method, path, handlers, meta)
+	engine.registerCORSRoute(method, path, meta)
+	engine.registerRouteMethod(method, path)
+	if engine.versionedRoutes == nil {
+		engine.versionedRoutes = make(map[handlerRouteKey]bool)
+	}
+	engine.versionedRoutes[key] = true
+
+	// The route skips the group middleware, which run once the request
+	// reaches the versioned route.
+	router := engine.Engine.Group("/")
+	router.Handlers = nil
+	router.Handle(method, path, engine.setContext, v.dispatch)
+}
+
+// checkVersionedRoute panics when a route is registered at the unversioned
+// path of versioned routes, which gin would reject with a less helpful
+// message.
+func (engine *Engine) checkVersionedRoute(method, path string) {
+	if engine.versionedRoutes[handlerRouteKey{Method: method, Path: path}] {
+		panic(fmt.Sprintf("fox: %s %s is already served by versioned routes, register it before them", method, path))
+	}
+}
+
+// dispatch serves a request to an unversioned path with the route of its
+// version.
+func (v *Versioning) dispatch(c *gin.Context) {
+	name := v.requestedVersion(c.Request)
+	if _, ok := v.versions[name]; !ok {
+		AbortWithError(c, ErrAPIVersionUnsupported)
+		return
+	}
+
+	base := v.group.router.BasePath()
+	segment := "/" + v.config.Prefix + name
+	if base == "/" {
+		base = ""
+	}
+	u := c.Request.URL
+	if !strings.HasPrefix(u.Path, base) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if u.RawPath != "" && strings.HasPrefix(u.RawPath, base) {
+		u.RawPath = base + segment + u.RawPath[len(base):]
+	}
+	u.Path = base + segment + u.Path[len(base):]
+	v.group.engine.HandleContext(c)
+}
+
+// requestedVersion returns the version named by the Accept media type or the
+// version header, or the default version.
+func (v *Versioning) requestedVersion(req *http.Request) string {
+	if v.config.Vendor != "" {
+		for accept := range strings.SplitSeq(req.Header.Get("Accept"), ",") {
+			if name := v.mediaTypeVersion(accept); name != "" {
+				return name
+			}
+		}
+	}
+	if v.config.Header != "" {
+		if name := strings.TrimSpace(req.Header.Get(v.config.Header)); name != "" {
+			return name
+		}
+	}
+	return v.config.Default
+}
+
+// mediaTypeVersion returns the version of a vendor media type.
+func (v *Versioning) mediaTypeVersion(accept string) string {
+	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+	if err != nil {
+		return ""
+	}
+	vendor, ok := strings.CutPrefix(mediaType, "application/vnd."+v.config.Vendor)
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexByte(vendor, '+'); i >= 0 {
+		vendor = vendor[:i]
+	}
+	if vendor == "" {
+		return params["version"]
+	}
+	name, _ := strings.CutPrefix(vendor, "."+v.config.Prefix)
+	if name == vendor {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,262 @@
+package fox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVersionedRouter(config VersioningConfig) *Engine {
+	router := New()
+	versions := router.Group("/api").Versioning(config)
+	versions.Version("1").GET("/users/:id", func(c *Context) string {
+		return "v1 " + c.Param("id") + " " + c.APIVersion()
+	})
+	v2 := versions.Version("2")
+	v2.GET("/users/:id", func(c *Context) string {
+		return "v2 " + c.Param("id") + " " + c.APIVersion()
+	})
+	v2.GET("/teams", func() string { return "v2 teams" })
+	return router
+}
+
+func TestVersioning_Path(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newVersionedRouter(VersioningConfig{})
+
+	w := serve(router, http.MethodGet, "/api/v1/users/7", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v1 7 1", w.Body.String())
+
+	w = serve(router, http.MethodGet, "/api/v2/users/7", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v2 7 2", w.Body.String())
+
+	// Without a header, vendor or default, unversioned paths are not routed.
+	w = serve(router, http.MethodGet, "/api/users/7", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVersioning_Header(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newVersionedRouter(VersioningConfig{Header: DefaultVersionHeader, Default: "1"})
+
+	t.Run("named version", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/users/7", "", "Accept-Version", "2")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "v2 7 2", w.Body.String())
+	})
+
+	t.Run("default version", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/users/7", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "v1 7 1", w.Body.String())
+	})
+
+	t.Run("route missing from the version", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/teams", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = serve(router, http.MethodGet, "/api/teams", "", "Accept-Version", "2")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "v2 teams", w.Body.String())
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/users/7", "", "Accept-Version", "9")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "API_VERSION_UNSUPPORTED")
+	})
+
+	t.Run("path takes precedence", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/v1/users/7", "", "Accept-Version", "2")
+		assert.Equal(t, "v1 7 1", w.Body.String())
+	})
+}
+
+func TestVersioning_MediaType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newVersionedRouter(VersioningConfig{Vendor: "acme", Header: DefaultVersionHeader})
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/vnd.acme.v2+json", "v2 7 2"},
+		{"application/json, application/vnd.acme.v1+json;q=0.9", "v1 7 1"},
+		{"application/vnd.acme+json; version=2", "v2 7 2"},
+	}
+	for _, tt := range tests {
+		w := serve(router, http.MethodGet, "/api/users/7", "", "Accept", tt.accept, "Accept-Version", "1")
+		assert.Equal(t, http.StatusOK, w.Code, tt.accept)
+		assert.Equal(t, tt.want, w.Body.String(), tt.accept)
+	}
+
+	// Other vendors and no default.
+	w := serve(router, http.MethodGet, "/api/users/7", "", "Accept", "application/vnd.other.v2+json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVersioning_Deprecation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	router := newVersionedRouter(VersioningConfig{
+		Default: "1",
+		Deprecated: map[string]VersionDeprecation{
+			"1": {At: deprecatedAt, Sunset: sunset, Link: "https://example.com/migrate"},
+		},
+	})
+
+	w := serve(router, http.MethodGet, "/api/users/7", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, w.Header().Get("Link"))
+
+	w = serve(router, http.MethodGet, "/api/v2/users/7", "")
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
+
+func TestVersioning_GroupMiddlewareRunOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := New()
+	api := router.Group("/api", gin.HandlerFunc(func(c *gin.Context) { calls++ }))
+	versions := api.Versioning(VersioningConfig{Default: "1"})
+	versions.Version("1").GET("/users", func() string { return "users" })
+
+	w := serve(router, http.MethodGet, "/api/users", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestVersioning_Options(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newVersionedRouter(VersioningConfig{Header: DefaultVersionHeader, Default: "1"})
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/users/7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, OPTIONS", w.Header().Get("Allow"))
+}
+
+func TestVersioning_UnversionedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New()
+	api := router.Group("/api")
+	api.GET("/status", func() string { return "status" })
+	versions := api.Versioning(VersioningConfig{Default: "1"})
+	v1 := versions.Version("1")
+	v1.GET("/status", func() string { return "v1 status" })
+	v1.GET("/users", func() string { return "v1 users" })
+	api.OPTIONS("/users", func(c *Context) { c.Status(http.StatusTeapot) })
+
+	t.Run("routes registered before take precedence", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/status", "")
+		assert.Equal(t, "status", w.Body.String())
+	})
+
+	t.Run("OPTIONS routes of unversioned paths", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/users", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/users", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET, OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("CORS preflight", func(t *testing.T) {
+		router := New()
+		api := router.Group("/api")
+		require.NoError(t, api.CORS(cors.Config{AllowOrigins: []string{"https://app.example.com"}}))
+		api.Versioning(VersioningConfig{Default: "1"}).Version("1").PUT("/users", func() string { return "v1" })
+
+		w := corsRequest(t, router, http.MethodOptions, "/api/users", "https://app.example.com", http.MethodPut)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "PUT", w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("routes registered after panic", func(t *testing.T) {
+		assert.PanicsWithValue(t, "fox: GET /api/users is already served by versioned routes, register it before them", func() {
+			api.GET("/users", func() string { return "users" })
+		})
+	})
+}
+
+func TestVersioning_RouteInfoAndManifest(t *testing.T) {
+	router := newVersionedRouter(VersioningConfig{
+		Header: DefaultVersionHeader,
+		Deprecated: map[string]VersionDeprecation{
+			"1": {At: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+
+	versions := map[string]string{}
+	for _, route := range router.HandlerRoutes() {
+		versions[route.Path] = route.Version
+	}
+	assert.Equal(t, map[string]string{
+		"/api/users/:id":    "",
+		"/api/teams":        "",
+		"/api/v1/users/:id": "1",
+		"/api/v2/users/:id": "2",
+		"/api/v2/teams":     "2",
+	}, versions)
+
+	manifest := RouteManifestFromEngine(router)
+	require.Len(t, manifest.Routes, 5)
+	data, err := json.Marshal(manifest.Routes)
+	require.NoError(t, err)
+
+	var routes []struct {
+		Path       string         `json:"path"`
+		Version    string         `json:"version"`
+		Deprecated bool           `json:"deprecated"`
+		Meta       map[string]any `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &routes))
+	for _, route := range routes {
+		switch route.Path {
+		case "/api/v1/users/:id":
+			assert.Equal(t, "1", route.Version)
+			assert.True(t, route.Deprecated)
+			assert.Equal(t, map[string]any{"name": "1", "deprecated": "2026-01-01T00:00:00Z"}, route.Meta["version"])
+		case "/api/users/:id", "/api/teams":
+			assert.Empty(t, route.Version)
+			assert.NotContains(t, route.Meta, "version")
+		default:
+			assert.Equal(t, "2", route.Version)
+			assert.False(t, route.Deprecated)
+		}
+	}
+}
+
+func TestVersioning_InvalidVersion(t *testing.T) {
+	versions := New().Group("/api").Versioning(VersioningConfig{})
+	assert.Panics(t, func() { versions.Version("") })
+	assert.Panics(t, func() { versions.Version("1/2") })
+}